@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// walkFields calls fn for every leaf field of the struct pointed to by v.
+func walkFields(v reflect.Value, fn func(field reflect.StructField, value reflect.Value) error) error {
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			if err := walkFields(value.Addr(), fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyDefaults(cfg *Config) error {
+	return walkFields(reflect.ValueOf(cfg), func(field reflect.StructField, value reflect.Value) error {
+		def, ok := field.Tag.Lookup("default")
+		if !ok {
+			return nil
+		}
+		if err := setValue(value, def); err != nil {
+			return fmt.Errorf("invalid default for %s: %w", field.Name, err)
+		}
+		return nil
+	})
+}
+
+func applyEnv(cfg *Config, lookup func(string) (string, bool, error)) error {
+	return walkFields(reflect.ValueOf(cfg), func(field reflect.StructField, value reflect.Value) error {
+		key := field.Tag.Get("env")
+		if key == "" {
+			return nil
+		}
+		raw, ok, err := lookup(key)
+		if err != nil {
+			return err
+		}
+		if !ok || (raw == "" && field.Tag.Get("allowempty") != "true") {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration that is safe to print.
+// Fields tagged secret:"true" are masked entirely; secret:"url" fields keep
+// everything but the password when they parse as URLs.
+func (c *Config) Redacted() Config {
+	out := *c
+	_ = walkFields(reflect.ValueOf(&out), func(field reflect.StructField, value reflect.Value) error {
+		mode := field.Tag.Get("secret")
+		if mode == "" || value.Kind() != reflect.String || value.String() == "" {
+			return nil
+		}
+		if mode == "url" {
+			if u, err := url.Parse(value.String()); err == nil && u.Scheme != "" && u.Host != "" {
+				value.SetString(u.Redacted())
+				return nil
+			}
+		}
+		value.SetString(redacted)
+		return nil
+	})
+	return out
+}
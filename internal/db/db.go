@@ -5,49 +5,174 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
-func Connect(dsn string) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", dsn)
+// Pool sizes a connection pool. Each field is passed to the sql.DB setter of
+// the same name.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Connect opens the primary database and applies migrations.
+func Connect(dsn string, pool Pool) (*sqlx.DB, error) {
+	db, err := open(dsn, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runMigrations(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// ConnectReplica opens a read-only replica. Migrations are left to the
+// primary.
+func ConnectReplica(dsn string, pool Pool) (*sqlx.DB, error) {
+	db, err := open(dsn, pool)
+	if err != nil {
+		return nil, fmt.Errorf("replica: %w", err)
+	}
+	return db, nil
+}
+
+func open(dsn string, pool Pool) (*sqlx.DB, error) {
+	sqlDB, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(30 * time.Minute)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return db, nil
+}
 
-	if err := runMigrations(db); err != nil {
-		return nil, err
-	}
+// TrigramExtension backs fuzzy user search. Creating it needs privileges the
+// service role should not have, so a database owner installs it once with
+// CREATE EXTENSION; without it search is disabled.
+const TrigramExtension = "pg_trgm"
 
-	return db, nil
+// HasExtension reports whether the named extension is installed.
+func HasExtension(db *sqlx.DB, name string) (bool, error) {
+	var installed bool
+	if err := db.Get(&installed, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname=$1)`, name); err != nil {
+		return false, fmt.Errorf("failed to check extension %s: %w", name, err)
+	}
+	return installed, nil
 }
 
 func runMigrations(db *sqlx.DB) error {
+	trigram, err := HasExtension(db, TrigramExtension)
+	if err != nil {
+		return err
+	}
+
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS friend_requests (
 			id SERIAL PRIMARY KEY,
 			from_user_id INT NOT NULL,
 			to_user_id INT NOT NULL,
-			status TEXT NOT NULL CHECK (status IN ('pending','accepted','rejected')),
+			status TEXT NOT NULL CHECK (status IN ('pending','accepted','rejected','held')),
 			created_at TIMESTAMPTZ DEFAULT NOW()
 			)`,
+		// Tables created before requests could be held need the wider
+		// check; swapping it takes a table lock, so only do it once.
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_constraint
+				WHERE conrelid = 'friend_requests'::regclass
+				AND conname = 'friend_requests_status_check'
+				AND pg_get_constraintdef(oid) LIKE '%held%'
+			) THEN
+				ALTER TABLE friend_requests DROP CONSTRAINT IF EXISTS friend_requests_status_check;
+				ALTER TABLE friend_requests ADD CONSTRAINT friend_requests_status_check
+					CHECK (status IN ('pending','accepted','rejected','held'));
+			END IF;
+		END $$`,
+		`CREATE INDEX IF NOT EXISTS friend_requests_from_user_created_idx
+			ON friend_requests (from_user_id, created_at)`,
 		`CREATE TABLE IF NOT EXISTS friendships (
 			id SERIAL PRIMARY KEY,
 			user_id INT NOT NULL,
 			friend_id INT NOT NULL,
 			UNIQUE (user_id, friend_id)
 			)`,
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			user_id BIGINT NOT NULL,
+			key TEXT NOT NULL,
+			request_hash TEXT NOT NULL,
+			status_code INT,
+			content_type TEXT,
+			response_body BYTEA,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			expires_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (user_id, key)
+			)`,
+		`CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			key TEXT PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			)`,
+		`CREATE TABLE IF NOT EXISTS users (
+			id BIGINT PRIMARY KEY,
+			username TEXT NOT NULL
+			)`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS synced_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch'`,
+		`CREATE TABLE IF NOT EXISTS export_jobs (
+			id TEXT PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			status TEXT NOT NULL CHECK (status IN ('pending','running','completed','failed')),
+			archive BYTEA,
+			error TEXT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			started_at TIMESTAMPTZ,
+			completed_at TIMESTAMPTZ
+			)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS export_jobs_unfinished_user_idx
+			ON export_jobs (user_id) WHERE status IN ('pending','running')`,
+		`CREATE INDEX IF NOT EXISTS users_username_prefix_idx
+			ON users (lower(username) text_pattern_ops) WHERE deleted_at IS NULL`,
+		`CREATE TABLE IF NOT EXISTS friend_invites (
+			id TEXT PRIMARY KEY,
+			inviter_id BIGINT NOT NULL,
+			single_use BOOLEAN NOT NULL DEFAULT FALSE,
+			uses BIGINT NOT NULL DEFAULT 0,
+			expires_at TIMESTAMPTZ NOT NULL,
+			revoked_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			)`,
+		`CREATE INDEX IF NOT EXISTS friend_invites_inviter_created_idx
+			ON friend_invites (inviter_id, created_at)`,
+	}
+	if trigram {
+		queries = append(queries, `CREATE INDEX IF NOT EXISTS users_username_trgm_idx
+			ON users USING gin (lower(username) gin_trgm_ops) WHERE deleted_at IS NULL`)
 	}
 
 	for _, q := range queries {
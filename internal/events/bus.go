@@ -0,0 +1,174 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Type identifies a friend graph change.
+type Type string
+
+const (
+	RequestCreated    Type = "friend.request.created"
+	RequestAccepted   Type = "friend.request.accepted"
+	RequestRejected   Type = "friend.request.rejected"
+	FriendshipCreated Type = "friendship.created"
+	FriendshipRemoved Type = "friendship.removed"
+)
+
+var (
+	// ErrSlowConsumer is reported when a subscriber's buffer fills up and it is
+	// disconnected; it may resubscribe from the last sequence it processed.
+	ErrSlowConsumer = errors.New("subscriber fell behind")
+	// ErrSequenceExpired is returned when a resume point is older than the
+	// retained history, or newer than any sequence this process assigned,
+	// which happens when the cursor was issued before a restart.
+	ErrSequenceExpired = errors.New("requested sequence is no longer retained")
+)
+
+const (
+	DefaultHistorySize = 1024
+	DefaultBufferSize  = 64
+)
+
+// Event is a friend graph change addressed to UserID. A single write usually
+// produces one event for each side of the relationship.
+type Event struct {
+	Sequence    uint64
+	Type        Type
+	UserID      int64
+	OtherUserID int64
+	RequestID   int64
+	OccurredAt  time.Time
+}
+
+// Bus fans out friend graph changes to in-process subscribers. Sequences are
+// assigned per process and a bounded history is kept for resuming.
+type Bus struct {
+	mu          sync.Mutex
+	seq         uint64
+	history     []Event
+	historySize int
+	bufferSize  int
+	subs        map[int64]map[*Subscription]struct{}
+}
+
+func NewBus(historySize, bufferSize int) *Bus {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Bus{
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subs:        make(map[int64]map[*Subscription]struct{}),
+	}
+}
+
+// Publish assigns sequences to the events and delivers them to subscribers.
+// It never blocks: subscribers whose buffers are full are dropped with
+// ErrSlowConsumer. Publishing on a nil Bus is a no-op.
+func (b *Bus) Publish(events ...Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, evt := range events {
+		b.seq++
+		evt.Sequence = b.seq
+		if evt.OccurredAt.IsZero() {
+			evt.OccurredAt = time.Now().UTC()
+		}
+
+		b.history = append(b.history, evt)
+		if len(b.history) > b.historySize {
+			b.history = b.history[len(b.history)-b.historySize:]
+		}
+
+		for sub := range b.subs[evt.UserID] {
+			select {
+			case sub.ch <- evt:
+			default:
+				b.removeLocked(sub, ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+// Subscribe registers for events addressed to userID. When after is non-zero
+// the retained events with a greater sequence are returned for replay before
+// anything is delivered on the subscription channel.
+func (b *Bus) Subscribe(userID int64, after uint64) (*Subscription, []Event, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	if after > b.seq {
+		return nil, nil, ErrSequenceExpired
+	}
+	if after > 0 && after < b.seq {
+		if len(b.history) == 0 || b.history[0].Sequence > after+1 {
+			return nil, nil, ErrSequenceExpired
+		}
+		for _, evt := range b.history {
+			if evt.Sequence > after && evt.UserID == userID {
+				backlog = append(backlog, evt)
+			}
+		}
+	}
+
+	sub := &Subscription{bus: b, userID: userID, ch: make(chan Event, b.bufferSize)}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*Subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	return sub, backlog, nil
+}
+
+func (b *Bus) removeLocked(sub *Subscription, err error) {
+	set, ok := b.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := set[sub]; !ok {
+		return
+	}
+	delete(set, sub)
+	if len(set) == 0 {
+		delete(b.subs, sub.userID)
+	}
+	sub.err = err
+	close(sub.ch)
+}
+
+// Subscription is a live feed of events for one user.
+type Subscription struct {
+	bus    *Bus
+	userID int64
+	ch     chan Event
+	err    error
+}
+
+// C returns the delivery channel. It is closed when the subscription ends.
+func (s *Subscription) C() <-chan Event {
+	return s.ch
+}
+
+// Err reports why the channel was closed; nil after a normal Close.
+func (s *Subscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.removeLocked(s, nil)
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishDeliversToUser(t *testing.T) {
+	bus := NewBus(10, 4)
+	sub, backlog, err := bus.Subscribe(1, 0)
+	require.NoError(t, err)
+	require.Empty(t, backlog)
+	defer sub.Close()
+
+	bus.Publish(
+		Event{Type: RequestCreated, UserID: 1, OtherUserID: 2, RequestID: 5},
+		Event{Type: RequestCreated, UserID: 2, OtherUserID: 1, RequestID: 5},
+	)
+
+	evt := <-sub.C()
+	require.Equal(t, uint64(1), evt.Sequence)
+	require.Equal(t, int64(2), evt.OtherUserID)
+	require.False(t, evt.OccurredAt.IsZero())
+	require.Empty(t, sub.C())
+}
+
+func TestSubscribeResumesFromSequence(t *testing.T) {
+	bus := NewBus(10, 4)
+	bus.Publish(
+		Event{Type: RequestCreated, UserID: 1},
+		Event{Type: RequestCreated, UserID: 2},
+		Event{Type: FriendshipCreated, UserID: 1},
+	)
+
+	sub, backlog, err := bus.Subscribe(1, 1)
+	require.NoError(t, err)
+	defer sub.Close()
+	require.Len(t, backlog, 1)
+	require.Equal(t, uint64(3), backlog[0].Sequence)
+	require.Equal(t, FriendshipCreated, backlog[0].Type)
+}
+
+func TestSubscribeExpiredSequence(t *testing.T) {
+	bus := NewBus(2, 4)
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: RequestCreated, UserID: 1})
+	}
+
+	_, _, err := bus.Subscribe(1, 1)
+	require.ErrorIs(t, err, ErrSequenceExpired)
+
+	sub, backlog, err := bus.Subscribe(1, 3)
+	require.NoError(t, err)
+	defer sub.Close()
+	require.Len(t, backlog, 2)
+}
+
+func TestSubscribeAheadOfBus(t *testing.T) {
+	// A cursor from before a restart is ahead of the fresh bus.
+	bus := NewBus(10, 4)
+	bus.Publish(Event{Type: RequestCreated, UserID: 1})
+
+	_, _, err := bus.Subscribe(1, 5)
+	require.ErrorIs(t, err, ErrSequenceExpired)
+
+	sub, backlog, err := bus.Subscribe(1, 1)
+	require.NoError(t, err)
+	defer sub.Close()
+	require.Empty(t, backlog)
+}
+
+func TestSlowConsumerIsDropped(t *testing.T) {
+	bus := NewBus(10, 1)
+	sub, _, err := bus.Subscribe(1, 0)
+	require.NoError(t, err)
+
+	bus.Publish(Event{UserID: 1}, Event{UserID: 1})
+
+	_, ok := <-sub.C()
+	require.True(t, ok)
+	_, ok = <-sub.C()
+	require.False(t, ok)
+	require.ErrorIs(t, sub.Err(), ErrSlowConsumer)
+
+	sub.Close()
+}
+
+func TestNilBusPublish(t *testing.T) {
+	var bus *Bus
+	require.NotPanics(t, func() { bus.Publish(Event{UserID: 1}) })
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"user-service/internal/models"
+)
+
+// Data is everything this service stores about one user.
+type Data struct {
+	UserID           int64                  `json:"user_id"`
+	GeneratedAt      time.Time              `json:"generated_at"`
+	FriendIDs        []int64                `json:"friend_ids"`
+	SentRequests     []models.FriendRequest `json:"sent_requests"`
+	ReceivedRequests []models.FriendRequest `json:"received_requests"`
+}
+
+// WriteArchive writes data as a zip holding data.json, friends.csv and
+// friend_requests.csv.
+func WriteArchive(w io.Writer, data Data) error {
+	archive := zip.NewWriter(w)
+
+	f, err := archive.Create("data.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+
+	friends := [][]string{{"friend_id"}}
+	for _, id := range data.FriendIDs {
+		friends = append(friends, []string{strconv.FormatInt(id, 10)})
+	}
+	if err := writeCSV(archive, "friends.csv", friends); err != nil {
+		return err
+	}
+
+	requests := [][]string{{"id", "direction", "from_user_id", "to_user_id", "status", "created_at"}}
+	for _, group := range []struct {
+		direction string
+		requests  []models.FriendRequest
+	}{
+		{"sent", data.SentRequests},
+		{"received", data.ReceivedRequests},
+	} {
+		for _, req := range group.requests {
+			requests = append(requests, []string{
+				strconv.FormatInt(req.ID, 10),
+				group.direction,
+				strconv.FormatInt(req.FromUserID, 10),
+				strconv.FormatInt(req.ToUserID, 10),
+				req.Status,
+				req.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	if err := writeCSV(archive, "friend_requests.csv", requests); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeCSV(archive *zip.Writer, name string, records [][]string) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+	return w.Error()
+}
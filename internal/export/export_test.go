@@ -0,0 +1,168 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/mocks"
+	"user-service/internal/models"
+)
+
+// memoryStore is a single-process Store for tests.
+type memoryStore struct {
+	jobs     map[string]*Job
+	archives map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: map[string]*Job{}, archives: map[string][]byte{}}
+}
+
+func (s *memoryStore) Create(_ context.Context, userID int64) (*Job, error) {
+	for _, job := range s.jobs {
+		if job.UserID == userID && (job.Status == StatusPending || job.Status == StatusRunning) {
+			return job, nil
+		}
+	}
+	job := &Job{ID: "job-" + string(rune('a'+len(s.jobs))), UserID: userID, Status: StatusPending, CreatedAt: time.Now()}
+	s.jobs[job.ID] = job
+	return job, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, userID int64, id string) (*Job, error) {
+	if job, ok := s.jobs[id]; ok && job.UserID == userID {
+		return job, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memoryStore) Archive(_ context.Context, userID int64, id string) ([]byte, error) {
+	if job, ok := s.jobs[id]; ok && job.UserID == userID && job.Status == StatusCompleted {
+		return s.archives[id], nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *memoryStore) Claim(context.Context) (*Job, error) {
+	for _, job := range s.jobs {
+		if job.Status == StatusPending {
+			job.Status = StatusRunning
+			return job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, id string, archive []byte) error {
+	s.jobs[id].Status = StatusCompleted
+	s.archives[id] = archive
+	return nil
+}
+
+func (s *memoryStore) Fail(_ context.Context, id, reason string) error {
+	s.jobs[id].Status = StatusFailed
+	s.jobs[id].Error = &reason
+	return nil
+}
+
+func readArchive(t *testing.T, archive []byte) map[string]string {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestExportSmallAccountImmediately(t *testing.T) {
+	friends := new(mocks.MockFriendRepository)
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	friends.On("CountFriends", mock.Anything, int64(1)).Return(int64(1), nil)
+	friends.On("CountAllRequests", mock.Anything, int64(1)).Return(int64(2), nil)
+	friends.On("ListFriends", mock.Anything, int64(1)).Return([]int64{2}, nil)
+	friends.On("ListAllRequests", mock.Anything, int64(1)).Return([]models.FriendRequest{
+		{ID: 10, FromUserID: 1, ToUserID: 3, Status: "rejected", CreatedAt: createdAt},
+		{ID: 11, FromUserID: 4, ToUserID: 1, Status: "pending", CreatedAt: createdAt},
+	}, nil)
+
+	svc := NewService(friends, newMemoryStore(), 10)
+	archive, job, err := svc.Export(context.Background(), 1)
+	require.NoError(t, err)
+	require.Nil(t, job)
+
+	files := readArchive(t, archive)
+	require.Equal(t, "friend_id\n2\n", files["friends.csv"])
+	require.Equal(t, "id,direction,from_user_id,to_user_id,status,created_at\n"+
+		"10,sent,1,3,rejected,2024-03-01T12:00:00Z\n"+
+		"11,received,4,1,pending,2024-03-01T12:00:00Z\n", files["friend_requests.csv"])
+	require.Contains(t, files["data.json"], `"sent_requests"`)
+	require.Contains(t, files["data.json"], `"received_requests"`)
+}
+
+func TestExportLargeAccountIsQueued(t *testing.T) {
+	friends := new(mocks.MockFriendRepository)
+	friends.On("CountFriends", mock.Anything, int64(1)).Return(int64(3), nil)
+	friends.On("CountAllRequests", mock.Anything, int64(1)).Return(int64(0), nil)
+	friends.On("ListFriends", mock.Anything, int64(1)).Return([]int64{2, 3, 4}, nil)
+	friends.On("ListAllRequests", mock.Anything, int64(1)).Return(nil, nil)
+
+	store := newMemoryStore()
+	svc := NewService(friends, store, 2)
+	archive, job, err := svc.Export(context.Background(), 1)
+	require.NoError(t, err)
+	require.Nil(t, archive)
+	require.Equal(t, StatusPending, job.Status)
+	// The rows are only loaded by the worker.
+	friends.AssertNotCalled(t, "ListFriends", mock.Anything, int64(1))
+
+	// Asking again while the job is queued returns the same job.
+	_, again, err := svc.Export(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, job.ID, again.ID)
+
+	processed, err := svc.ProcessNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.Equal(t, StatusCompleted, store.jobs[job.ID].Status)
+
+	archive, err = svc.Archive(context.Background(), 1, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, "friend_id\n2\n3\n4\n", readArchive(t, archive)["friends.csv"])
+
+	_, err = svc.Archive(context.Background(), 2, job.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	processed, err = svc.ProcessNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, processed)
+}
+
+func TestProcessNextMarksFailedJobs(t *testing.T) {
+	friends := new(mocks.MockFriendRepository)
+	friends.On("ListFriends", mock.Anything, int64(1)).Return(nil, errors.New("db down"))
+
+	store := newMemoryStore()
+	job, err := store.Create(context.Background(), 1)
+	require.NoError(t, err)
+
+	processed, err := NewService(friends, store, 0).ProcessNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.Equal(t, StatusFailed, store.jobs[job.ID].Status)
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"user-service/internal/logging"
+	"user-service/internal/models"
+)
+
+// Source is the part of the friend repository an export reads.
+type Source interface {
+	ListFriends(ctx context.Context, userID int64) ([]int64, error)
+	ListAllRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error)
+	CountFriends(ctx context.Context, userID int64) (int64, error)
+	CountAllRequests(ctx context.Context, userID int64) (int64, error)
+}
+
+// Service builds data exports. Accounts with at most syncLimit rows are
+// exported while the client waits; larger ones are queued in the store and
+// built by ProcessNext.
+type Service struct {
+	source    Source
+	store     Store
+	syncLimit int
+	now       func() time.Time
+}
+
+func NewService(source Source, store Store, syncLimit int) *Service {
+	return &Service{source: source, store: store, syncLimit: syncLimit, now: time.Now}
+}
+
+// Collect gathers the user's friendships and friend requests.
+func (s *Service) Collect(ctx context.Context, userID int64) (Data, error) {
+	data := Data{
+		UserID:           userID,
+		GeneratedAt:      s.now().UTC(),
+		FriendIDs:        []int64{},
+		SentRequests:     []models.FriendRequest{},
+		ReceivedRequests: []models.FriendRequest{},
+	}
+
+	friends, err := s.source.ListFriends(ctx, userID)
+	if err != nil {
+		return data, fmt.Errorf("failed to list friends: %w", err)
+	}
+	data.FriendIDs = append(data.FriendIDs, friends...)
+
+	requests, err := s.source.ListAllRequests(ctx, userID)
+	if err != nil {
+		return data, fmt.Errorf("failed to list friend requests: %w", err)
+	}
+	for _, req := range requests {
+		if req.FromUserID == userID {
+			data.SentRequests = append(data.SentRequests, req)
+		} else {
+			data.ReceivedRequests = append(data.ReceivedRequests, req)
+		}
+	}
+	return data, nil
+}
+
+// Export returns the finished archive for a small account, or the queued
+// job for a large one. Rows are counted first so a large account is never
+// loaded while the client waits.
+func (s *Service) Export(ctx context.Context, userID int64) ([]byte, *Job, error) {
+	friends, err := s.source.CountFriends(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count friends: %w", err)
+	}
+	requests, err := s.source.CountAllRequests(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count friend requests: %w", err)
+	}
+	if friends+requests <= int64(s.syncLimit) {
+		data, err := s.Collect(ctx, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		archive, err := build(data)
+		return archive, nil, err
+	}
+
+	job, err := s.store.Create(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to queue export: %w", err)
+	}
+	return nil, job, nil
+}
+
+func (s *Service) Job(ctx context.Context, userID int64, id string) (*Job, error) {
+	return s.store.Get(ctx, userID, id)
+}
+
+func (s *Service) Archive(ctx context.Context, userID int64, id string) ([]byte, error) {
+	return s.store.Archive(ctx, userID, id)
+}
+
+// ProcessNext builds the archive of the oldest queued job. It reports
+// whether there was a job to process.
+func (s *Service) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := s.store.Claim(ctx)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	data, err := s.Collect(ctx, job.UserID)
+	var archive []byte
+	if err == nil {
+		archive, err = build(data)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("data export failed", "job_id", job.ID, "user_id", job.UserID, "error", err)
+		return true, s.store.Fail(ctx, job.ID, "failed to build export")
+	}
+	return true, s.store.Complete(ctx, job.ID, archive)
+}
+
+func build(data Data) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to write export archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,148 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// staleRunningAfter is how long a running job may go without finishing
+// before another worker assumes its replica died and takes it over.
+const staleRunningAfter = 15 * time.Minute
+
+// Job is an asynchronous export. The archive itself is loaded separately.
+type Job struct {
+	ID          string     `db:"id"`
+	UserID      int64      `db:"user_id"`
+	Status      string     `db:"status"`
+	Error       *string    `db:"error"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// Store persists export jobs so any replica can build or serve them. Get and
+// Archive return sql.ErrNoRows for jobs that do not exist or belong to
+// another user.
+type Store interface {
+	// Create queues a job for userID, or returns the user's unfinished job
+	// if there already is one.
+	Create(ctx context.Context, userID int64) (*Job, error)
+	Get(ctx context.Context, userID int64, id string) (*Job, error)
+	Archive(ctx context.Context, userID int64, id string) ([]byte, error)
+	// Claim marks the oldest pending job running and returns it, or nil
+	// when there is nothing to do.
+	Claim(ctx context.Context) (*Job, error)
+	Complete(ctx context.Context, id string, archive []byte) error
+	Fail(ctx context.Context, id, reason string) error
+}
+
+// PostgresStore keeps jobs in the export_jobs table.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const jobColumns = `id, user_id, status, error, created_at, completed_at`
+
+func (s *PostgresStore) Create(ctx context.Context, userID int64) (*Job, error) {
+	// The partial unique index allows one unfinished job per user, so two
+	// concurrent requests end up sharing it.
+	var created []Job
+	err := s.db.SelectContext(ctx, &created, `
+INSERT INTO export_jobs (id, user_id, status)
+VALUES ($1, $2, 'pending')
+ON CONFLICT (user_id) WHERE status IN ('pending','running') DO NOTHING
+RETURNING `+jobColumns, uuid.NewString(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 1 {
+		return &created[0], nil
+	}
+
+	var job Job
+	err = s.db.GetContext(ctx, &job, `
+SELECT `+jobColumns+` FROM export_jobs
+WHERE user_id=$1 AND status IN ('pending','running')
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *PostgresStore) Get(ctx context.Context, userID int64, id string) (*Job, error) {
+	var job Job
+	err := s.db.GetContext(ctx, &job, `
+SELECT `+jobColumns+` FROM export_jobs WHERE id=$1 AND user_id=$2
+`, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *PostgresStore) Archive(ctx context.Context, userID int64, id string) ([]byte, error) {
+	var archive []byte
+	err := s.db.GetContext(ctx, &archive, `
+SELECT archive FROM export_jobs WHERE id=$1 AND user_id=$2 AND status='completed'
+`, id, userID)
+	return archive, err
+}
+
+func (s *PostgresStore) Claim(ctx context.Context) (*Job, error) {
+	var jobs []Job
+	err := s.db.SelectContext(ctx, &jobs, `
+UPDATE export_jobs SET status='running', started_at=NOW()
+WHERE id = (
+SELECT id FROM export_jobs
+WHERE status='pending' OR (status='running' AND started_at < NOW() - make_interval(secs => $1))
+ORDER BY created_at
+FOR UPDATE SKIP LOCKED
+LIMIT 1
+)
+RETURNING `+jobColumns, staleRunningAfter.Seconds())
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, id string, archive []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+UPDATE export_jobs SET status='completed', archive=$2, completed_at=NOW() WHERE id=$1
+`, id, archive)
+	return err
+}
+
+func (s *PostgresStore) Fail(ctx context.Context, id, reason string) error {
+	_, err := s.db.ExecContext(ctx, `
+UPDATE export_jobs SET status='failed', error=$2, completed_at=NOW() WHERE id=$1
+`, id, reason)
+	return err
+}
+
+// Sweep deletes finished jobs, and their archives, older than retention.
+func (s *PostgresStore) Sweep(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+DELETE FROM export_jobs
+WHERE status IN ('completed','failed') AND created_at < NOW() - make_interval(secs => $1)
+`, retention.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep export jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -6,6 +6,7 @@ import (
 
 	authpb "user-service/proto/auth"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -20,7 +21,10 @@ func NewAuthClient(addr string) (*AuthClient, error) {
 		return nil, fmt.Errorf("auth gRPC address is required")
 	}
 
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial auth gRPC: %w", err)
 	}
@@ -42,6 +46,10 @@ func (c *AuthClient) GetUser(ctx context.Context, userID int64) (*authpb.GetUser
 	return c.client.GetUser(ctx, &authpb.GetUserRequest{UserId: userID})
 }
 
+func (c *AuthClient) GetUserByUsername(ctx context.Context, username string) (*authpb.GetUserResponse, error) {
+	return c.client.GetUserByUsername(ctx, &authpb.GetUserByUsernameRequest{Username: username})
+}
+
 func (c *AuthClient) ValidateToken(ctx context.Context, token string) (*authpb.ValidateTokenResponse, error) {
 	return c.client.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: token})
 }
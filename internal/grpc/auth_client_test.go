@@ -0,0 +1,53 @@
+package igrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authpb "user-service/proto/auth"
+)
+
+// stubAuthServer answers GetUserByUsername from a fixed set of users.
+type stubAuthServer struct {
+	authpb.UnimplementedAuthServiceServer
+	users map[string]int64
+}
+
+func (s *stubAuthServer) GetUserByUsername(_ context.Context, req *authpb.GetUserByUsernameRequest) (*authpb.GetUserResponse, error) {
+	id, ok := s.users[req.GetUsername()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &authpb.GetUserResponse{Id: id, Username: req.GetUsername()}, nil
+}
+
+func startStubAuthServer(t *testing.T, srv authpb.AuthServiceServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	authpb.RegisterAuthServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestAuthClientGetUserByUsername(t *testing.T) {
+	addr := startStubAuthServer(t, &stubAuthServer{users: map[string]int64{"bob": 2}})
+	client, err := NewAuthClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	resp, err := client.GetUserByUsername(context.Background(), "bob")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), resp.GetId())
+
+	_, err = client.GetUserByUsername(context.Background(), "nobody")
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
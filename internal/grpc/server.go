@@ -3,12 +3,17 @@ package igrpc
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"net"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"user-service/internal/events"
+	"user-service/internal/models"
 	"user-service/internal/repositories"
 	authpb "user-service/proto/auth"
 	userpb "user-service/proto/user"
@@ -23,20 +28,21 @@ type UserGRPCServer struct {
 	userpb.UnimplementedUserInternalServer
 	friends    repositories.FriendRepository
 	authClient AuthClientAPI
+	bus        *events.Bus
 }
 
-func NewUserGRPCServer(friends repositories.FriendRepository, authClient AuthClientAPI) *UserGRPCServer {
-	return &UserGRPCServer{friends: friends, authClient: authClient}
+func NewUserGRPCServer(friends repositories.FriendRepository, authClient AuthClientAPI, bus *events.Bus) *UserGRPCServer {
+	return &UserGRPCServer{friends: friends, authClient: authClient, bus: bus}
 }
 
-func StartGRPCServer(ctx context.Context, addr string, friends repositories.FriendRepository, authClient AuthClientAPI) (*grpc.Server, error) {
+func StartGRPCServer(ctx context.Context, addr string, friends repositories.FriendRepository, authClient AuthClientAPI, bus *events.Bus) (*grpc.Server, error) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
 
-	srv := grpc.NewServer()
-	userpb.RegisterUserInternalServer(srv, NewUserGRPCServer(friends, authClient))
+	srv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	userpb.RegisterUserInternalServer(srv, NewUserGRPCServer(friends, authClient, bus))
 
 	go func() {
 		<-ctx.Done()
@@ -45,7 +51,7 @@ func StartGRPCServer(ctx context.Context, addr string, friends repositories.Frie
 
 	go func() {
 		if err := srv.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
-			log.Printf("gRPC server error: %v", err)
+			slog.Error("gRPC server error", "error", err)
 		}
 	}()
 
@@ -68,14 +74,229 @@ func (s *UserGRPCServer) GetUser(ctx context.Context, req *userpb.GetUserRequest
 	return &userpb.GetUserResponse{Id: user.Id, Username: user.Username, CreatedAt: user.CreatedAt}, nil
 }
 
+const (
+	// maxBulkUsers caps the number of distinct ids accepted by BulkUsers.
+	maxBulkUsers = 500
+	// bulkUsersConcurrency bounds parallel lookups against auth-service.
+	bulkUsersConcurrency = 8
+)
+
+// BulkUsers looks up each distinct id and returns whatever was found. Ids
+// unknown to auth-service are listed in missing_ids and other failures in
+// errors, so a single deleted account does not fail the whole batch.
 func (s *UserGRPCServer) BulkUsers(ctx context.Context, req *userpb.BulkUsersRequest) (*userpb.BulkUsersResponse, error) {
-	responses := make([]*userpb.GetUserResponse, 0, len(req.GetIds()))
-	for _, id := range req.GetIds() {
-		user, err := s.authClient.GetUser(ctx, id)
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to fetch user %d: %v", id, err)
+	ids := uniqueIDs(req.GetIds())
+	if len(ids) > maxBulkUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d distinct ids are allowed", maxBulkUsers)
+	}
+
+	type result struct {
+		user *authpb.GetUserResponse
+		err  error
+	}
+	results := make([]result, len(ids))
+	sem := make(chan struct{}, bulkUsersConcurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			user, err := s.authClient.GetUser(ctx, id)
+			results[i] = result{user: user, err: err}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	resp := &userpb.BulkUsersResponse{Users: make([]*userpb.GetUserResponse, 0, len(ids))}
+	for i, id := range ids {
+		res := results[i]
+		if res.err == nil {
+			resp.Users = append(resp.Users, &userpb.GetUserResponse{Id: res.user.Id, Username: res.user.Username, CreatedAt: res.user.CreatedAt})
+			continue
+		}
+		st := status.Convert(res.err)
+		if st.Code() == codes.NotFound {
+			resp.MissingIds = append(resp.MissingIds, id)
+			continue
+		}
+		resp.Errors = append(resp.Errors, &userpb.BulkUserError{Id: id, Code: st.Code().String(), Message: st.Message()})
+	}
+	return resp, nil
+}
+
+// uniqueIDs drops repeated ids while keeping first-seen order.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// maxBatchCandidates bounds AreFriendsBatch so one call cannot scan an
+// arbitrarily large friend list.
+const maxBatchCandidates = 1000
+
+func (s *UserGRPCServer) ListFriends(ctx context.Context, req *userpb.ListFriendsRequest) (*userpb.ListFriendsResponse, error) {
+	friends, err := s.friends.ListFriends(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list friends: %v", err)
+	}
+	return &userpb.ListFriendsResponse{FriendIds: friends}, nil
+}
+
+func (s *UserGRPCServer) ListIncomingRequests(ctx context.Context, req *userpb.ListFriendRequestsRequest) (*userpb.ListFriendRequestsResponse, error) {
+	requests, err := s.friends.GetIncomingRequests(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list incoming requests: %v", err)
+	}
+	return &userpb.ListFriendRequestsResponse{Requests: toFriendRequests(requests)}, nil
+}
+
+func (s *UserGRPCServer) ListOutgoingRequests(ctx context.Context, req *userpb.ListFriendRequestsRequest) (*userpb.ListFriendRequestsResponse, error) {
+	requests, err := s.friends.GetOutgoingRequests(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list outgoing requests: %v", err)
+	}
+	return &userpb.ListFriendRequestsResponse{Requests: toFriendRequests(requests)}, nil
+}
+
+func (s *UserGRPCServer) CountFriends(ctx context.Context, req *userpb.CountFriendsRequest) (*userpb.CountFriendsResponse, error) {
+	count, err := s.friends.CountFriends(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count friends: %v", err)
+	}
+	return &userpb.CountFriendsResponse{Count: count}, nil
+}
+
+func (s *UserGRPCServer) DeleteUserData(ctx context.Context, req *userpb.DeleteUserDataRequest) (*userpb.DeleteUserDataResponse, error) {
+	if req.GetUserId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	deleted, err := s.friends.DeleteUserData(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete user data: %v", err)
+	}
+	return &userpb.DeleteUserDataResponse{
+		RemovedFriendIds: deleted.FriendIDs,
+		DeletedRequests:  deleted.Requests,
+	}, nil
+}
+
+func (s *UserGRPCServer) AreFriendsBatch(ctx context.Context, req *userpb.AreFriendsBatchRequest) (*userpb.AreFriendsBatchResponse, error) {
+	candidates := req.GetCandidateIds()
+	if len(candidates) > maxBatchCandidates {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d candidate_ids are allowed", maxBatchCandidates)
+	}
+
+	result := make(map[int64]bool, len(candidates))
+	for _, id := range candidates {
+		result[id] = false
+	}
+	if len(candidates) == 0 {
+		return &userpb.AreFriendsBatchResponse{AreFriends: result}, nil
+	}
+
+	friends, err := s.friends.FilterFriends(ctx, req.GetUserId(), candidates)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check friendships: %v", err)
+	}
+	for _, id := range friends {
+		result[id] = true
+	}
+	return &userpb.AreFriendsBatchResponse{AreFriends: result}, nil
+}
+
+func toFriendRequests(requests []models.FriendRequest) []*userpb.FriendRequest {
+	out := make([]*userpb.FriendRequest, 0, len(requests))
+	for _, r := range requests {
+		out = append(out, &userpb.FriendRequest{
+			Id:         r.ID,
+			FromUserId: r.FromUserID,
+			ToUserId:   r.ToUserID,
+			Status:     r.Status,
+			CreatedAt:  r.CreatedAt.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return out
+}
+
+// WatchFriendships streams friend graph changes for a user. Clients that fall
+// behind are disconnected with ResourceExhausted and should reconnect with the
+// last sequence they processed.
+func (s *UserGRPCServer) WatchFriendships(req *userpb.WatchFriendshipsRequest, stream grpc.ServerStreamingServer[userpb.FriendshipEvent]) error {
+	if req.GetUserId() <= 0 {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if s.bus == nil {
+		return status.Error(codes.Unavailable, "friendship events are not available")
+	}
+
+	sub, backlog, err := s.bus.Subscribe(req.GetUserId(), req.GetAfterSequence())
+	if err != nil {
+		if errors.Is(err, events.ErrSequenceExpired) {
+			return status.Errorf(codes.OutOfRange, "sequence %d cannot be resumed; reload and watch from 0", req.GetAfterSequence())
+		}
+		return status.Errorf(codes.Internal, "failed to subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	last := req.GetAfterSequence()
+	for _, evt := range backlog {
+		if err := stream.Send(toFriendshipEvent(evt)); err != nil {
+			return err
 		}
-		responses = append(responses, &userpb.GetUserResponse{Id: user.Id, Username: user.Username, CreatedAt: user.CreatedAt})
+		last = evt.Sequence
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case evt, ok := <-sub.C():
+			if !ok {
+				if errors.Is(sub.Err(), events.ErrSlowConsumer) {
+					return status.Errorf(codes.ResourceExhausted, "subscriber fell behind; resume after sequence %d", last)
+				}
+				return nil
+			}
+			if evt.Sequence <= last {
+				continue
+			}
+			if err := stream.Send(toFriendshipEvent(evt)); err != nil {
+				return err
+			}
+			last = evt.Sequence
+		}
+	}
+}
+
+var friendshipEventTypes = map[events.Type]userpb.FriendshipEventType{
+	events.RequestCreated:    userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_CREATED,
+	events.RequestAccepted:   userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED,
+	events.RequestRejected:   userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_REJECTED,
+	events.FriendshipCreated: userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED,
+	events.FriendshipRemoved: userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_REMOVED,
+}
+
+func toFriendshipEvent(evt events.Event) *userpb.FriendshipEvent {
+	return &userpb.FriendshipEvent{
+		Sequence:    evt.Sequence,
+		Type:        friendshipEventTypes[evt.Type],
+		UserId:      evt.UserID,
+		OtherUserId: evt.OtherUserID,
+		RequestId:   evt.RequestID,
+		OccurredAt:  evt.OccurredAt.UTC().Format(time.RFC3339Nano),
 	}
-	return &userpb.BulkUsersResponse{Users: responses}, nil
 }
@@ -3,18 +3,25 @@ package igrpc
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"user-service/internal/events"
 	"user-service/internal/mocks"
+	"user-service/internal/models"
+	authpb "user-service/proto/auth"
 	userpb "user-service/proto/user"
 )
 
 func TestAreFriends(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
-	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient))
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
 
 	mockFriends.On("AreFriends", mock.Anything, int64(1), int64(2)).Return(true, nil).Once()
 
@@ -27,7 +34,7 @@ func TestAreFriends(t *testing.T) {
 
 func TestAreFriendsFalse(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
-	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient))
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
 
 	mockFriends.On("AreFriends", mock.Anything, int64(2), int64(3)).Return(false, nil).Once()
 
@@ -37,3 +44,197 @@ func TestAreFriendsFalse(t *testing.T) {
 
 	mockFriends.AssertExpectations(t)
 }
+
+func TestListFriends(t *testing.T) {
+	mockFriends := new(mocks.MockFriendRepository)
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
+
+	mockFriends.On("ListFriends", mock.Anything, int64(1)).Return([]int64{2, 3}, nil).Once()
+
+	resp, err := srv.ListFriends(context.Background(), &userpb.ListFriendsRequest{UserId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, resp.GetFriendIds())
+
+	mockFriends.AssertExpectations(t)
+}
+
+func TestListOutgoingRequests(t *testing.T) {
+	mockFriends := new(mocks.MockFriendRepository)
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
+
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockFriends.On("GetOutgoingRequests", mock.Anything, int64(1)).
+		Return([]models.FriendRequest{{ID: 4, FromUserID: 1, ToUserID: 5, Status: "pending", CreatedAt: createdAt}}, nil).Once()
+
+	resp, err := srv.ListOutgoingRequests(context.Background(), &userpb.ListFriendRequestsRequest{UserId: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.GetRequests(), 1)
+	assert.Equal(t, int64(5), resp.GetRequests()[0].GetToUserId())
+	assert.Equal(t, "2025-01-02T03:04:05Z", resp.GetRequests()[0].GetCreatedAt())
+
+	mockFriends.AssertExpectations(t)
+}
+
+func TestCountFriendsError(t *testing.T) {
+	mockFriends := new(mocks.MockFriendRepository)
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
+
+	mockFriends.On("CountFriends", mock.Anything, int64(1)).Return(int64(0), assert.AnError).Once()
+
+	_, err := srv.CountFriends(context.Background(), &userpb.CountFriendsRequest{UserId: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	mockFriends.AssertExpectations(t)
+}
+
+func TestDeleteUserData(t *testing.T) {
+	mockFriends := new(mocks.MockFriendRepository)
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
+
+	mockFriends.On("DeleteUserData", mock.Anything, int64(1)).Return(models.DeletedUserData{FriendIDs: []int64{2, 3}, Requests: 4}, nil).Once()
+
+	resp, err := srv.DeleteUserData(context.Background(), &userpb.DeleteUserDataRequest{UserId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, resp.GetRemovedFriendIds())
+	assert.Equal(t, int64(4), resp.GetDeletedRequests())
+
+	_, err = srv.DeleteUserData(context.Background(), &userpb.DeleteUserDataRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockFriends.AssertExpectations(t)
+}
+
+func TestAreFriendsBatch(t *testing.T) {
+	mockFriends := new(mocks.MockFriendRepository)
+	srv := NewUserGRPCServer(mockFriends, new(mocks.MockAuthClient), nil)
+
+	mockFriends.On("FilterFriends", mock.Anything, int64(1), []int64{2, 3, 4}).Return([]int64{3}, nil).Once()
+
+	resp, err := srv.AreFriendsBatch(context.Background(), &userpb.AreFriendsBatchRequest{UserId: 1, CandidateIds: []int64{2, 3, 4}})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{2: false, 3: true, 4: false}, resp.GetAreFriends())
+
+	mockFriends.AssertExpectations(t)
+}
+
+func TestAreFriendsBatchTooManyCandidates(t *testing.T) {
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), nil)
+
+	_, err := srv.AreFriendsBatch(context.Background(), &userpb.AreFriendsBatchRequest{UserId: 1, CandidateIds: make([]int64, maxBatchCandidates+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestBulkUsersPartialResults(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), mockAuth, nil)
+
+	mockAuth.On("GetUser", mock.Anything, int64(1)).Return(&authpb.GetUserResponse{Id: 1, Username: "alice"}, nil).Once()
+	mockAuth.On("GetUser", mock.Anything, int64(2)).Return((*authpb.GetUserResponse)(nil), status.Error(codes.NotFound, "user not found")).Once()
+	mockAuth.On("GetUser", mock.Anything, int64(3)).Return((*authpb.GetUserResponse)(nil), status.Error(codes.Unavailable, "auth down")).Once()
+	mockAuth.On("GetUser", mock.Anything, int64(4)).Return(&authpb.GetUserResponse{Id: 4, Username: "dave"}, nil).Once()
+
+	resp, err := srv.BulkUsers(context.Background(), &userpb.BulkUsersRequest{Ids: []int64{4, 1, 2, 4, 3, 1}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetUsers(), 2)
+	assert.Equal(t, int64(4), resp.GetUsers()[0].GetId())
+	assert.Equal(t, int64(1), resp.GetUsers()[1].GetId())
+	assert.Equal(t, []int64{2}, resp.GetMissingIds())
+	require.Len(t, resp.GetErrors(), 1)
+	assert.Equal(t, int64(3), resp.GetErrors()[0].GetId())
+	assert.Equal(t, codes.Unavailable.String(), resp.GetErrors()[0].GetCode())
+
+	mockAuth.AssertExpectations(t)
+}
+
+func TestBulkUsersTooManyIDs(t *testing.T) {
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), nil)
+
+	ids := make([]int64, maxBulkUsers+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	_, err := srv.BulkUsers(context.Background(), &userpb.BulkUsersRequest{Ids: ids})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *userpb.FriendshipEvent
+}
+
+func (s *fakeWatchStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWatchStream) Send(evt *userpb.FriendshipEvent) error {
+	s.sent <- evt
+	return nil
+}
+
+func TestWatchFriendshipsReplaysAndStreams(t *testing.T) {
+	bus := events.NewBus(10, 4)
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), bus)
+	bus.Publish(events.Event{Type: events.RequestCreated, UserID: 1, OtherUserID: 2, RequestID: 9})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWatchStream{ctx: ctx, sent: make(chan *userpb.FriendshipEvent, 16)}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.WatchFriendships(&userpb.WatchFriendshipsRequest{UserId: 1, AfterSequence: 0}, stream)
+	}()
+
+	require.Eventually(t, func() bool {
+		bus.Publish(events.Event{Type: events.FriendshipCreated, UserID: 1, OtherUserID: 2})
+		return len(stream.sent) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	evt := <-stream.sent
+	assert.Equal(t, userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED, evt.GetType())
+	assert.Equal(t, int64(2), evt.GetOtherUserId())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchFriendshipsResume(t *testing.T) {
+	bus := events.NewBus(10, 4)
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), bus)
+	bus.Publish(
+		events.Event{Type: events.RequestCreated, UserID: 1, RequestID: 9},
+		events.Event{Type: events.RequestAccepted, UserID: 1, RequestID: 9},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeWatchStream{ctx: ctx, sent: make(chan *userpb.FriendshipEvent, 16)}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.WatchFriendships(&userpb.WatchFriendshipsRequest{UserId: 1, AfterSequence: 1}, stream)
+	}()
+
+	evt := <-stream.sent
+	assert.Equal(t, uint64(2), evt.GetSequence())
+	assert.Equal(t, userpb.FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED, evt.GetType())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchFriendshipsExpiredSequence(t *testing.T) {
+	bus := events.NewBus(1, 4)
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), bus)
+	bus.Publish(events.Event{UserID: 1}, events.Event{UserID: 1}, events.Event{UserID: 1})
+
+	stream := &fakeWatchStream{ctx: context.Background(), sent: make(chan *userpb.FriendshipEvent, 16)}
+	err := srv.WatchFriendships(&userpb.WatchFriendshipsRequest{UserId: 1, AfterSequence: 1}, stream)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
+
+func TestWatchFriendshipsAheadOfBus(t *testing.T) {
+	bus := events.NewBus(10, 4)
+	srv := NewUserGRPCServer(new(mocks.MockFriendRepository), new(mocks.MockAuthClient), bus)
+	bus.Publish(events.Event{UserID: 1})
+
+	stream := &fakeWatchStream{ctx: context.Background(), sent: make(chan *userpb.FriendshipEvent, 16)}
+	err := srv.WatchFriendships(&userpb.WatchFriendshipsRequest{UserId: 1, AfterSequence: 42}, stream)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
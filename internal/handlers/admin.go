@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/middleware"
+	"user-service/internal/models"
+	"user-service/internal/problem"
+	"user-service/internal/repositories"
+	"user-service/internal/telemetry"
+)
+
+// AdminHandler serves the /admin routes used by support staff. Every action,
+// including reads, is audited with the acting admin and the target user.
+type AdminHandler struct {
+	friends repositories.FriendRepository
+	audit   *telemetry.AuditEmitter
+}
+
+func NewAdminHandler(friends repositories.FriendRepository, audit *telemetry.AuditEmitter) *AdminHandler {
+	return &AdminHandler{friends: friends, audit: audit}
+}
+
+func (h *AdminHandler) ListFriends(c *gin.Context) {
+	targetID, ok := h.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	friends, err := h.friends.ListFriends(c.Request.Context(), targetID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to list friends", &targetID)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to fetch friends")
+		return
+	}
+	if friends == nil {
+		friends = []int64{}
+	}
+
+	h.emitAudit(c, "INFO", "Admin listed friends", &targetID)
+	c.JSON(nethttp.StatusOK, gin.H{"user_id": targetID, "friend_ids": friends})
+}
+
+func (h *AdminHandler) ListRequests(c *gin.Context) {
+	targetID, ok := h.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	incoming, err := h.friends.GetIncomingRequests(ctx, targetID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to list friend requests", &targetID)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load requests")
+		return
+	}
+	outgoing, err := h.friends.GetOutgoingRequests(ctx, targetID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to list friend requests", &targetID)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load requests")
+		return
+	}
+	if incoming == nil {
+		incoming = []models.FriendRequest{}
+	}
+	if outgoing == nil {
+		outgoing = []models.FriendRequest{}
+	}
+
+	h.emitAudit(c, "INFO", "Admin listed friend requests", &targetID)
+	c.JSON(nethttp.StatusOK, gin.H{"user_id": targetID, "incoming": incoming, "outgoing": outgoing})
+}
+
+func (h *AdminHandler) RemoveFriendship(c *gin.Context) {
+	targetID, ok := h.targetUserID(c)
+	if !ok {
+		return
+	}
+	friendID, err := strconv.ParseInt(c.Param("friendId"), 10, 64)
+	if err != nil {
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid friend id")
+		return
+	}
+
+	removed, err := h.friends.RemoveFriendship(c.Request.Context(), targetID, friendID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to remove friendship", &targetID)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to remove friendship")
+		return
+	}
+	if !removed {
+		h.emitAudit(c, "ERROR", "admin friendship removal: not friends", &targetID)
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeFriendshipNotFound, "friendship not found")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Admin removed friendship with '"+strconv.FormatInt(friendID, 10)+"'", &targetID)
+	c.JSON(nethttp.StatusOK, gin.H{"status": "removed"})
+}
+
+func (h *AdminHandler) PurgePendingRequests(c *gin.Context) {
+	targetID, ok := h.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	purged, err := h.friends.PurgePendingRequests(c.Request.Context(), targetID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to purge pending requests", &targetID)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to purge requests")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Admin purged "+strconv.FormatInt(purged, 10)+" pending requests", &targetID)
+	c.JSON(nethttp.StatusOK, gin.H{"purged": purged})
+}
+
+const (
+	defaultHeldRequestsLimit = 100
+	maxHeldRequestsLimit     = 500
+)
+
+// ListHeldRequests returns the oldest requests held by the spam heuristics.
+func (h *AdminHandler) ListHeldRequests(c *gin.Context) {
+	limit := defaultHeldRequestsLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid limit")
+			return
+		}
+		limit = min(parsed, maxHeldRequestsLimit)
+	}
+
+	held, err := h.friends.ListHeldRequests(c.Request.Context(), limit)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "admin failed to list held requests", nil)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load requests")
+		return
+	}
+	if held == nil {
+		held = []models.FriendRequest{}
+	}
+
+	h.emitAudit(c, "INFO", "Admin listed held friend requests", nil)
+	c.JSON(nethttp.StatusOK, gin.H{"requests": held})
+}
+
+// ReleaseHeldRequest delivers a held request to its recipient.
+func (h *AdminHandler) ReleaseHeldRequest(c *gin.Context) {
+	h.reviewHeldRequest(c, true)
+}
+
+// DiscardHeldRequest rejects a held request without notifying the recipient.
+func (h *AdminHandler) DiscardHeldRequest(c *gin.Context) {
+	h.reviewHeldRequest(c, false)
+}
+
+func (h *AdminHandler) reviewHeldRequest(c *gin.Context, release bool) {
+	reqID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid request id")
+		return
+	}
+	verb, done := "discard", "discarded"
+	if release {
+		verb, done = "release", "released"
+	}
+
+	req, err := h.friends.ReviewHeldRequest(c.Request.Context(), reqID, release)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondProblem(c, nethttp.StatusNotFound, problem.CodeRequestNotFound, "held request not found")
+			return
+		}
+		h.emitAudit(c, "ERROR", "admin failed to "+verb+" held request", nil)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to update request")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Admin "+done+" held friend request '"+strconv.FormatInt(req.ID, 10)+"'", &req.FromUserID)
+	c.JSON(nethttp.StatusOK, req)
+}
+
+func (h *AdminHandler) targetUserID(c *gin.Context) (int64, bool) {
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid user id")
+		return 0, false
+	}
+	return targetID, true
+}
+
+func (h *AdminHandler) emitAudit(c *gin.Context, level, text string, targetUserID *int64) {
+	if h.audit == nil {
+		return
+	}
+	h.audit.Emit(c.Request.Context(), telemetry.AuditEntry{
+		Level:          level,
+		Text:           text,
+		RequestID:      middleware.RequestIDFromContext(c),
+		UserID:         userIDFromContext(c),
+		IdentitySource: identitySourceFromContext(c),
+		TargetUserID:   targetUserID,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/mocks"
+	"user-service/internal/models"
+	"user-service/internal/telemetry"
+)
+
+func setupAdminRouter(handler *AdminHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(99))
+		c.Next()
+	})
+	r.GET("/admin/users/:id/friends", handler.ListFriends)
+	r.GET("/admin/users/:id/requests", handler.ListRequests)
+	r.DELETE("/admin/users/:id/friends/:friendId", handler.RemoveFriendship)
+	r.POST("/admin/users/:id/requests/purge", handler.PurgePendingRequests)
+	return r
+}
+
+func expectAdminAudit(t *testing.T, publisher *mocks.MockPublisher, level, text string, targetUserID int64) {
+	t.Helper()
+	publisher.On("Publish", mock.Anything, telemetry.AuditRoutingKey, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		event := args.Get(2).(telemetry.Envelope)
+		require.Equal(t, level, event.Payload.Level)
+		require.Equal(t, text, event.Payload.Text)
+		require.NotNil(t, event.UserID)
+		require.Equal(t, int64(99), *event.UserID)
+		require.NotNil(t, event.TargetUserID)
+		require.Equal(t, targetUserID, *event.TargetUserID)
+	}).Once()
+}
+
+func TestAdminListRequestsIsAudited(t *testing.T) {
+	mockRepo := new(mocks.MockFriendRepository)
+	mockPublisher := new(mocks.MockPublisher)
+	handler := NewAdminHandler(mockRepo, telemetry.NewAuditEmitter(mockPublisher, "user-service", "local"))
+	router := setupAdminRouter(handler)
+
+	mockRepo.On("GetIncomingRequests", mock.Anything, int64(5)).Return([]models.FriendRequest{{ID: 1, FromUserID: 2, ToUserID: 5, Status: "pending"}}, nil)
+	mockRepo.On("GetOutgoingRequests", mock.Anything, int64(5)).Return([]models.FriendRequest(nil), nil)
+	expectAdminAudit(t, mockPublisher, "INFO", "Admin listed friend requests", 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/5/requests", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Incoming []models.FriendRequest `json:"incoming"`
+		Outgoing []models.FriendRequest `json:"outgoing"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Incoming, 1)
+	require.NotNil(t, body.Outgoing)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestAdminRemoveFriendship(t *testing.T) {
+	mockRepo := new(mocks.MockFriendRepository)
+	mockPublisher := new(mocks.MockPublisher)
+	handler := NewAdminHandler(mockRepo, telemetry.NewAuditEmitter(mockPublisher, "user-service", "local"))
+	router := setupAdminRouter(handler)
+
+	mockRepo.On("RemoveFriendship", mock.Anything, int64(5), int64(6)).Return(true, nil).Once()
+	mockRepo.On("RemoveFriendship", mock.Anything, int64(5), int64(7)).Return(false, nil).Once()
+	expectAdminAudit(t, mockPublisher, "INFO", "Admin removed friendship with '6'", 5)
+	expectAdminAudit(t, mockPublisher, "ERROR", "admin friendship removal: not friends", 5)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/users/5/friends/6", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/users/5/friends/7", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestAdminPurgePendingRequests(t *testing.T) {
+	mockRepo := new(mocks.MockFriendRepository)
+	mockPublisher := new(mocks.MockPublisher)
+	handler := NewAdminHandler(mockRepo, telemetry.NewAuditEmitter(mockPublisher, "user-service", "local"))
+	router := setupAdminRouter(handler)
+
+	mockRepo.On("PurgePendingRequests", mock.Anything, int64(5)).Return(int64(3), nil).Once()
+	mockRepo.On("PurgePendingRequests", mock.Anything, int64(8)).Return(int64(0), errors.New("db down")).Once()
+	expectAdminAudit(t, mockPublisher, "INFO", "Admin purged 3 pending requests", 5)
+	expectAdminAudit(t, mockPublisher, "ERROR", "admin failed to purge pending requests", 8)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/users/5/requests/purge", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"purged":3}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/users/8/requests/purge", nil))
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestAdminReviewHeldRequest(t *testing.T) {
+	mockRepo := new(mocks.MockFriendRepository)
+	mockPublisher := new(mocks.MockPublisher)
+	handler := NewAdminHandler(mockRepo, telemetry.NewAuditEmitter(mockPublisher, "user-service", "local"))
+	router := setupAdminRouter(handler)
+	router.POST("/admin/requests/:id/release", handler.ReleaseHeldRequest)
+	router.POST("/admin/requests/:id/discard", handler.DiscardHeldRequest)
+
+	mockRepo.On("ReviewHeldRequest", mock.Anything, int64(7), true).Return(&models.FriendRequest{ID: 7, FromUserID: 3, ToUserID: 4, Status: "pending"}, nil).Once()
+	mockRepo.On("ReviewHeldRequest", mock.Anything, int64(8), false).Return((*models.FriendRequest)(nil), sql.ErrNoRows).Once()
+	expectAdminAudit(t, mockPublisher, "INFO", "Admin released held friend request '7'", 3)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/requests/7/release", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/requests/8/discard", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
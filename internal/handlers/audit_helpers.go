@@ -1,32 +1,26 @@
 package handlers
 
 import (
-	"strconv"
-
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
 
-func requestIDFromHeader(c *gin.Context) string {
-	requestID := c.GetHeader("X-Request-ID")
-	if requestID == "" {
-		requestID = uuid.NewString()
-	}
-	return requestID
-}
+	"user-service/internal/middleware"
+)
 
+// userIDFromContext returns the caller established by the authentication
+// middleware. Identity headers are never read here; the gateway provider
+// verifies them before they reach the context.
 func userIDFromContext(c *gin.Context) *int64 {
 	if userIDVal, ok := c.Get("userID"); ok {
 		if userID, ok := userIDVal.(int64); ok {
 			return &userID
 		}
 	}
+	return nil
+}
 
-	if header := c.GetHeader("X-User-ID"); header != "" {
-		if parsed, err := strconv.ParseInt(header, 10, 64); err == nil {
-			return &parsed
-		}
+func identitySourceFromContext(c *gin.Context) string {
+	if identity, ok := middleware.IdentityFromContext(c); ok {
+		return identity.Source
 	}
-
-	return nil
+	return ""
 }
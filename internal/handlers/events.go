@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"user-service/internal/events"
+	"user-service/internal/metrics"
+	"user-service/internal/problem"
+	"user-service/internal/realtime"
+)
+
+// EventStreamHandler pushes friend graph changes for the authenticated user
+// over Server-Sent Events, or over a WebSocket when the client asks to upgrade.
+type EventStreamHandler struct {
+	bus       *events.Bus
+	registry  *realtime.Registry
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+func NewEventStreamHandler(bus *events.Bus, registry *realtime.Registry, heartbeat time.Duration) *EventStreamHandler {
+	return &EventStreamHandler{bus: bus, registry: registry, heartbeat: heartbeat}
+}
+
+type streamEvent struct {
+	Sequence    uint64      `json:"sequence"`
+	Type        events.Type `json:"type"`
+	UserID      int64       `json:"user_id"`
+	OtherUserID int64       `json:"other_user_id"`
+	RequestID   int64       `json:"request_id,omitempty"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+}
+
+func toStreamEvent(evt events.Event) streamEvent {
+	return streamEvent{
+		Sequence:    evt.Sequence,
+		Type:        evt.Type,
+		UserID:      evt.UserID,
+		OtherUserID: evt.OtherUserID,
+		RequestID:   evt.RequestID,
+		OccurredAt:  evt.OccurredAt,
+	}
+}
+
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	userIDVal, _ := c.Get("userID")
+	userID := userIDVal.(int64)
+
+	transport := metrics.TransportSSE
+	if websocket.IsWebSocketUpgrade(c.Request) {
+		transport = metrics.TransportWebSocket
+	}
+
+	ctx, release, err := h.registry.Register(c.Request.Context(), userID, transport)
+	if err != nil {
+		if errors.Is(err, realtime.ErrTooManyStreams) {
+			respondProblem(c, nethttp.StatusTooManyRequests, problem.CodeTooManyStreams, "too many open streams")
+			return
+		}
+		respondProblem(c, nethttp.StatusServiceUnavailable, problem.CodeUnavailable, "event stream unavailable")
+		return
+	}
+	defer release()
+
+	// SSE clients resume with Last-Event-ID; WebSocket clients pass ?after=.
+	after, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+	if q := c.Query("after"); q != "" {
+		after, _ = strconv.ParseUint(q, 10, 64)
+	}
+
+	resync := false
+	sub, backlog, err := h.bus.Subscribe(userID, after)
+	if errors.Is(err, events.ErrSequenceExpired) {
+		// The client missed events we no longer hold; tell it to reload.
+		resync = true
+		sub, backlog, err = h.bus.Subscribe(userID, 0)
+	}
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to subscribe")
+		return
+	}
+	defer sub.Close()
+
+	if transport == metrics.TransportWebSocket {
+		h.serveWebSocket(ctx, c, sub, backlog, resync)
+		return
+	}
+	h.serveSSE(ctx, c, sub, backlog, resync)
+}
+
+func (h *EventStreamHandler) serveSSE(ctx context.Context, c *gin.Context, sub *events.Subscription, backlog []events.Event, resync bool) {
+	w := c.Writer
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(nethttp.StatusOK)
+
+	if resync {
+		if _, err := fmt.Fprint(w, "event: resync\ndata: {}\n\n"); err != nil {
+			return
+		}
+	}
+	for _, evt := range backlog {
+		if err := writeSSEEvent(w, evt); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case evt, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, evt); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeSSEEvent(w gin.ResponseWriter, evt events.Event) error {
+	data, err := json.Marshal(toStreamEvent(evt))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.Sequence, evt.Type, data)
+	return err
+}
+
+func (h *EventStreamHandler) serveWebSocket(ctx context.Context, c *gin.Context, sub *events.Subscription, backlog []events.Event, resync bool) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written an error response.
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The reader only exists to process pongs and notice the client leaving.
+	conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if resync {
+		if err := conn.WriteJSON(gin.H{"type": "resync"}); err != nil {
+			return
+		}
+	}
+	for _, evt := range backlog {
+		if err := conn.WriteJSON(toStreamEvent(evt)); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.heartbeat)); err != nil {
+				return
+			}
+		case evt, ok := <-sub.C():
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber fell behind"), time.Now().Add(time.Second))
+				return
+			}
+			if err := conn.WriteJSON(toStreamEvent(evt)); err != nil {
+				return
+			}
+		}
+	}
+}
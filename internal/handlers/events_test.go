@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/events"
+	"user-service/internal/realtime"
+)
+
+func setupEventStreamServer(t *testing.T, bus *events.Bus, registry *realtime.Registry) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	r.GET("/events/stream", NewEventStreamHandler(bus, registry, 50*time.Millisecond).Stream)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readSSEEvent returns the fields of the next block on the stream; comment
+// lines such as heartbeats are reported under the "comment" key.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			fields["comment"] = line
+			continue
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		fields[key] = value
+	}
+}
+
+func TestEventStreamSSE(t *testing.T) {
+	bus := events.NewBus(10, 4)
+	srv := setupEventStreamServer(t, bus, realtime.NewRegistry(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/stream", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	require.Equal(t, ": ping", readSSEEvent(t, reader)["comment"])
+
+	bus.Publish(events.Event{Type: events.RequestCreated, UserID: 1, OtherUserID: 2, RequestID: 3})
+	bus.Publish(events.Event{Type: events.RequestCreated, UserID: 2, OtherUserID: 1, RequestID: 3})
+
+	evt := readSSEEvent(t, reader)
+	for evt["event"] == "" {
+		evt = readSSEEvent(t, reader)
+	}
+	require.Equal(t, "1", evt["id"])
+	require.Equal(t, string(events.RequestCreated), evt["event"])
+
+	var payload streamEvent
+	require.NoError(t, json.Unmarshal([]byte(evt["data"]), &payload))
+	require.Equal(t, int64(2), payload.OtherUserID)
+	require.Equal(t, int64(3), payload.RequestID)
+}
+
+func TestEventStreamSSEResumeAndResync(t *testing.T) {
+	bus := events.NewBus(2, 4)
+	srv := setupEventStreamServer(t, bus, realtime.NewRegistry(0))
+	for i := 0; i < 4; i++ {
+		bus.Publish(events.Event{Type: events.FriendshipCreated, UserID: 1})
+	}
+
+	open := func(lastEventID string) (*bufio.Reader, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/stream", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", lastEventID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return bufio.NewReader(resp.Body), func() { cancel(); resp.Body.Close() }
+	}
+
+	reader, closeStream := open("3")
+	require.Equal(t, "4", readSSEEvent(t, reader)["id"])
+	closeStream()
+
+	reader, closeStream = open("1")
+	defer closeStream()
+	require.Equal(t, "resync", readSSEEvent(t, reader)["event"])
+}
+
+func TestEventStreamTooManyStreams(t *testing.T) {
+	registry := realtime.NewRegistry(1)
+	_, release, err := registry.Register(context.Background(), 1, "sse")
+	require.NoError(t, err)
+	defer release()
+
+	srv := setupEventStreamServer(t, events.NewBus(10, 4), registry)
+	resp, err := http.Get(srv.URL + "/events/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
+func TestEventStreamWebSocket(t *testing.T) {
+	bus := events.NewBus(10, 4)
+	registry := realtime.NewRegistry(0)
+	srv := setupEventStreamServer(t, bus, registry)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/events/stream", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return registry.Active() == 1 }, time.Second, 5*time.Millisecond)
+	bus.Publish(events.Event{Type: events.RequestAccepted, UserID: 1, OtherUserID: 4, RequestID: 8})
+
+	var payload streamEvent
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, conn.ReadJSON(&payload))
+	require.Equal(t, events.RequestAccepted, payload.Type)
+	require.Equal(t, int64(4), payload.OtherUserID)
+
+	registry.CloseAll()
+	_, _, err = conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	require.Eventually(t, func() bool { return registry.Active() == 0 }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/export"
+	"user-service/internal/middleware"
+	"user-service/internal/problem"
+	"user-service/internal/telemetry"
+)
+
+const exportContentType = "application/zip"
+
+type ExportHandler struct {
+	exports *export.Service
+	audit   *telemetry.AuditEmitter
+}
+
+func NewExportHandler(exports *export.Service, audit *telemetry.AuditEmitter) *ExportHandler {
+	return &ExportHandler{exports: exports, audit: audit}
+}
+
+type exportJobResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	StatusURL   string     `json:"status_url"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
+
+// Export answers with the archive of the caller's data when it is small,
+// otherwise with 202 and the queued job to poll.
+func (h *ExportHandler) Export(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	archive, job, err := h.exports.Export(c.Request.Context(), userID)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "data export failed")
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to export data")
+		return
+	}
+	if job == nil {
+		h.emitAudit(c, "INFO", "Data export downloaded")
+		h.sendArchive(c, userID, archive)
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Data export "+job.ID+" requested")
+	statusURL := c.Request.URL.Path + "/" + job.ID
+	c.Header("Location", statusURL)
+	c.JSON(nethttp.StatusAccepted, jobResponse(job, statusURL))
+}
+
+func (h *ExportHandler) Status(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	job, err := h.exports.Job(c.Request.Context(), userID, c.Param("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeExportNotFound, "export not found")
+		return
+	}
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load export")
+		return
+	}
+	c.JSON(nethttp.StatusOK, jobResponse(job, c.Request.URL.Path))
+}
+
+func (h *ExportHandler) Download(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	job, err := h.exports.Job(ctx, userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeExportNotFound, "export not found")
+		return
+	}
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load export")
+		return
+	}
+	switch job.Status {
+	case export.StatusCompleted:
+	case export.StatusFailed:
+		respondProblem(c, nethttp.StatusConflict, problem.CodeExportFailed, "export failed; request a new one")
+		return
+	default:
+		respondProblem(c, nethttp.StatusConflict, problem.CodeExportNotReady, "export is still being generated")
+		return
+	}
+
+	archive, err := h.exports.Archive(ctx, userID, id)
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load export")
+		return
+	}
+	h.emitAudit(c, "INFO", "Data export "+id+" downloaded")
+	h.sendArchive(c, userID, archive)
+}
+
+func (h *ExportHandler) sendArchive(c *gin.Context, userID int64, archive []byte) {
+	c.Header("Content-Disposition", `attachment; filename="user-`+strconv.FormatInt(userID, 10)+`-export.zip"`)
+	c.Data(nethttp.StatusOK, exportContentType, archive)
+}
+
+func jobResponse(job *export.Job, statusURL string) exportJobResponse {
+	resp := exportJobResponse{
+		ID:          job.ID,
+		Status:      job.Status,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		StatusURL:   statusURL,
+	}
+	if job.Status == export.StatusCompleted {
+		resp.DownloadURL = statusURL + "/download"
+	}
+	return resp
+}
+
+func (h *ExportHandler) emitAudit(c *gin.Context, level, text string) {
+	if h.audit == nil {
+		return
+	}
+	h.audit.Emit(c.Request.Context(), telemetry.AuditEntry{
+		Level:          level,
+		Text:           text,
+		RequestID:      middleware.RequestIDFromContext(c),
+		UserID:         userIDFromContext(c),
+		IdentitySource: identitySourceFromContext(c),
+	})
+}
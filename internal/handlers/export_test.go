@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/export"
+	"user-service/internal/mocks"
+	"user-service/internal/problem"
+)
+
+// stubExportStore holds at most one job.
+type stubExportStore struct {
+	job *export.Job
+}
+
+func (s *stubExportStore) Create(_ context.Context, userID int64) (*export.Job, error) {
+	s.job = &export.Job{ID: "job-1", UserID: userID, Status: export.StatusPending, CreatedAt: time.Now()}
+	return s.job, nil
+}
+
+func (s *stubExportStore) Get(_ context.Context, userID int64, id string) (*export.Job, error) {
+	if s.job == nil || s.job.ID != id || s.job.UserID != userID {
+		return nil, sql.ErrNoRows
+	}
+	return s.job, nil
+}
+
+func (s *stubExportStore) Archive(context.Context, int64, string) ([]byte, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (s *stubExportStore) Claim(context.Context) (*export.Job, error)     { return nil, nil }
+func (s *stubExportStore) Complete(context.Context, string, []byte) error { return nil }
+func (s *stubExportStore) Fail(context.Context, string, string) error     { return nil }
+
+func setupExportRouter(handler *ExportHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	r.GET("/users/me/export", handler.Export)
+	r.GET("/users/me/export/:id", handler.Status)
+	r.GET("/users/me/export/:id/download", handler.Download)
+	return r
+}
+
+func TestExportReturnsArchiveForSmallAccount(t *testing.T) {
+	friendRepo := new(mocks.MockFriendRepository)
+	friendRepo.On("CountFriends", mock.Anything, int64(1)).Return(int64(1), nil)
+	friendRepo.On("CountAllRequests", mock.Anything, int64(1)).Return(int64(0), nil)
+	friendRepo.On("ListFriends", mock.Anything, int64(1)).Return([]int64{2}, nil)
+	friendRepo.On("ListAllRequests", mock.Anything, int64(1)).Return(nil, nil)
+	router := setupExportRouter(NewExportHandler(export.NewService(friendRepo, &stubExportStore{}, 10), nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/export", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="user-1-export.zip"`, rec.Header().Get("Content-Disposition"))
+}
+
+func TestExportQueuesLargeAccount(t *testing.T) {
+	friendRepo := new(mocks.MockFriendRepository)
+	friendRepo.On("CountFriends", mock.Anything, int64(1)).Return(int64(2), nil)
+	friendRepo.On("CountAllRequests", mock.Anything, int64(1)).Return(int64(0), nil)
+	router := setupExportRouter(NewExportHandler(export.NewService(friendRepo, &stubExportStore{}, 1), nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/export", nil))
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, "/users/me/export/job-1", rec.Header().Get("Location"))
+	var resp exportJobResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, export.StatusPending, resp.Status)
+	require.Empty(t, resp.DownloadURL)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/export/job-1/download", nil))
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), problem.CodeExportNotReady)
+}
+
+func TestExportStatusUnknownJob(t *testing.T) {
+	router := setupExportRouter(NewExportHandler(export.NewService(new(mocks.MockFriendRepository), &stubExportStore{}, 10), nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/export/missing", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Contains(t, rec.Body.String(), problem.CodeExportNotFound)
+}
@@ -3,12 +3,17 @@ package handlers
 import (
 	"context"
 	"database/sql"
+	"errors"
 	nethttp "net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"user-service/internal/metrics"
+	"user-service/internal/middleware"
+	"user-service/internal/problem"
 	"user-service/internal/repositories"
 	"user-service/internal/services"
 	"user-service/internal/telemetry"
@@ -18,86 +23,147 @@ type FriendHandler struct {
 	friends repositories.FriendRepository
 	users   *services.UserService
 	audit   *telemetry.AuditEmitter
+	policy  *services.RequestPolicy
 }
 
-func NewFriendHandler(friends repositories.FriendRepository, users *services.UserService, audit *telemetry.AuditEmitter) *FriendHandler {
-	return &FriendHandler{friends: friends, users: users, audit: audit}
+// NewFriendHandler creates the friend request handlers. policy may be nil to
+// skip the daily quota and spam checks.
+func NewFriendHandler(friends repositories.FriendRepository, users *services.UserService, audit *telemetry.AuditEmitter, policy *services.RequestPolicy) *FriendHandler {
+	return &FriendHandler{friends: friends, users: users, audit: audit, policy: policy}
 }
 
+// sendRequestBody names the recipient by ID or by username, not both.
 type sendRequestBody struct {
-	ToUserID int64 `json:"to_user_id" binding:"required"`
+	ToUserID   int64  `json:"to_user_id" binding:"required_without=ToUsername,excluded_with=ToUsername"`
+	ToUsername string `json:"to_username" binding:"omitempty,max=64"`
 }
 
 func (h *FriendHandler) SendRequest(c *gin.Context) {
-	requestID := requestIDFromHeader(c)
+	requestID := middleware.RequestIDFromContext(c)
 	userID := userIDFromContext(c)
 	var body sendRequestBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		h.emitAudit(c.Request.Context(), "ERROR", "invalid request payload", requestID, userID)
+		h.emitAudit(c, "ERROR", "invalid request payload", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusBadRequest, gin.H{"error": "invalid request body"})
+		middleware.WriteProblem(c, bindingProblem(err, &body))
 		return
 	}
 
 	if userID == nil {
-		h.emitAudit(c.Request.Context(), "ERROR", "internal error", requestID, nil)
+		h.emitAudit(c, "ERROR", "internal error", requestID, nil)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondProblem(c, nethttp.StatusUnauthorized, problem.CodeUnauthorized, "unauthorized")
 		return
 	}
 	fromUserID := *userID
 
+	ctx := c.Request.Context()
 	toUserID := body.ToUserID
+	if body.ToUsername != "" {
+		target, err := h.users.GetUserByUsername(ctx, body.ToUsername)
+		switch {
+		case errors.Is(err, services.ErrAmbiguousUsername):
+			h.emitAudit(c, "ERROR", "target username is ambiguous", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusBadRequest, problem.CodeAmbiguousUsername, "username matches more than one user; use to_user_id")
+			return
+		case status.Code(err) == codes.NotFound:
+			h.emitAudit(c, "ERROR", "target user not found", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusNotFound, problem.CodeUserNotFound, "target user not found")
+			return
+		case err != nil:
+			h.emitAudit(c, "ERROR", "internal error", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to look up username")
+			return
+		}
+		toUserID = target.ID
+	}
+
 	if toUserID == fromUserID {
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusBadRequest, gin.H{"error": "cannot send request to yourself"})
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeSelfFriendRequest, "cannot send request to yourself")
 		return
 	}
 
-	ctx := c.Request.Context()
-	if _, err := h.users.GetUserByID(ctx, toUserID); err != nil {
-		h.emitAudit(ctx, "ERROR", "target user not found", requestID, userID)
-		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusNotFound, gin.H{"error": "target user not found"})
-		return
+	if body.ToUsername == "" {
+		if _, err := h.users.GetUserByID(ctx, toUserID); err != nil {
+			h.emitAudit(c, "ERROR", "target user not found", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusNotFound, problem.CodeUserNotFound, "target user not found")
+			return
+		}
 	}
 
 	exists, err := h.friends.HasPendingRequest(ctx, fromUserID, toUserID)
 	if err != nil {
-		h.emitAudit(ctx, "ERROR", "internal error", requestID, userID)
+		h.emitAudit(c, "ERROR", "internal error", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to check requests"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to check requests")
 		return
 	}
 	if exists {
-		h.emitAudit(ctx, "ERROR", "pending friend request already exists", requestID, userID)
+		h.emitAudit(c, "ERROR", "pending friend request already exists", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusConflict, gin.H{"error": "pending friend request already exists"})
+		respondProblem(c, nethttp.StatusConflict, problem.CodeFriendRequestExists, "pending friend request already exists")
 		return
 	}
 
-	friends, err := h.friends.AreFriends(ctx, fromUserID, toUserID)
+	friends, err := h.friends.AreFriendsOnPrimary(ctx, fromUserID, toUserID)
 	if err != nil {
-		h.emitAudit(ctx, "ERROR", "internal error", requestID, userID)
+		h.emitAudit(c, "ERROR", "internal error", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to check friendship"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to check friendship")
 		return
 	}
 	if friends {
-		h.emitAudit(ctx, "ERROR", "users are already friends", requestID, userID)
+		h.emitAudit(c, "ERROR", "users are already friends", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
-		c.JSON(nethttp.StatusConflict, gin.H{"error": "users are already friends"})
+		respondProblem(c, nethttp.StatusConflict, problem.CodeAlreadyFriends, "users are already friends")
+		return
+	}
+
+	var verdict services.RequestVerdict
+	if h.policy != nil {
+		verdict, err = h.policy.Evaluate(ctx, fromUserID)
+		if err != nil {
+			h.emitAudit(c, "ERROR", "internal error", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to check request quota")
+			return
+		}
+		if verdict.QuotaExceeded {
+			h.emitAudit(c, "ERROR", "daily friend request limit reached", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusTooManyRequests, problem.CodeDailyLimitReached, "daily friend request limit reached")
+			return
+		}
+	}
+
+	if verdict.Hold {
+		req, err := h.friends.CreateHeldRequest(ctx, fromUserID, toUserID)
+		if err != nil {
+			h.emitAudit(c, "ERROR", "internal error", requestID, userID)
+			metrics.IncFriendRequest(metrics.StatusFailed)
+			respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to create request")
+			return
+		}
+		h.emitAudit(c, "WARN", "Friend request to '"+strconv.FormatInt(toUserID, 10)+"' held for review (rejection ratio "+strconv.FormatFloat(verdict.RejectionRatio, 'f', 2, 64)+")", requestID, userID)
+		metrics.IncFriendRequest(metrics.StatusSuccess)
+		c.JSON(nethttp.StatusCreated, req)
 		return
 	}
 
 	req, err := h.friends.CreateRequest(ctx, fromUserID, toUserID)
 	if err != nil {
-		h.emitAudit(ctx, "ERROR", "internal error", requestID, userID)
+		h.emitAudit(c, "ERROR", "internal error", requestID, userID)
 		metrics.IncFriendRequest(metrics.StatusFailed)
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to create request")
 		return
 	}
 
-	h.emitAudit(ctx, "INFO", "Friend request sent to '"+strconv.FormatInt(toUserID, 10)+"'", requestID, userID)
+	h.emitAudit(c, "INFO", "Friend request sent to '"+strconv.FormatInt(toUserID, 10)+"'", requestID, userID)
 	metrics.IncFriendRequest(metrics.StatusSuccess)
 	c.JSON(nethttp.StatusCreated, req)
 }
@@ -108,7 +174,7 @@ func (h *FriendHandler) ListIncoming(c *gin.Context) {
 
 	requests, err := h.friends.GetIncomingRequests(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to load requests"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load requests")
 		return
 	}
 
@@ -116,7 +182,7 @@ func (h *FriendHandler) ListIncoming(c *gin.Context) {
 	for _, req := range requests {
 		sender, err := h.users.GetUserByID(c.Request.Context(), req.FromUserID)
 		if err != nil {
-			c.JSON(nethttp.StatusBadGateway, gin.H{"error": "failed to fetch requester info"})
+			respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch requester info")
 			return
 		}
 		resp = append(resp, gin.H{
@@ -144,16 +210,16 @@ func (h *FriendHandler) handleDecision(c *gin.Context, action func(ctx context.C
 	reqID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		inc(metrics.StatusFailed)
-		c.JSON(nethttp.StatusBadRequest, gin.H{"error": "invalid request id"})
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid request id")
 		return
 	}
 
-	requestID := requestIDFromHeader(c)
+	requestID := middleware.RequestIDFromContext(c)
 	userID := userIDFromContext(c)
 	if userID == nil {
-		h.emitAudit(c.Request.Context(), "ERROR", "internal error", requestID, nil)
+		h.emitAudit(c, "ERROR", "internal error", requestID, nil)
 		inc(metrics.StatusFailed)
-		c.JSON(nethttp.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		respondProblem(c, nethttp.StatusUnauthorized, problem.CodeUnauthorized, "unauthorized")
 		return
 	}
 	userIDVal := *userID
@@ -161,24 +227,24 @@ func (h *FriendHandler) handleDecision(c *gin.Context, action func(ctx context.C
 	ctx := c.Request.Context()
 	if err := action(ctx, reqID, userIDVal); err != nil {
 		if err == sql.ErrNoRows {
-			h.emitAudit(ctx, "ERROR", "friend request not found", requestID, userID)
+			h.emitAudit(c, "ERROR", "friend request not found", requestID, userID)
 			inc(metrics.StatusFailed)
-			c.JSON(nethttp.StatusNotFound, gin.H{"error": "request not found"})
+			respondProblem(c, nethttp.StatusNotFound, problem.CodeRequestNotFound, "request not found")
 			return
 		}
 		if err == repositories.ErrRequestForbidden {
-			h.emitAudit(ctx, "ERROR", "not allowed to "+verb+" this request", requestID, userID)
+			h.emitAudit(c, "ERROR", "not allowed to "+verb+" this request", requestID, userID)
 			inc(metrics.StatusFailed)
-			c.JSON(nethttp.StatusForbidden, gin.H{"error": "not allowed to " + verb + " this request"})
+			respondProblem(c, nethttp.StatusForbidden, problem.CodeForbidden, "not allowed to "+verb+" this request")
 			return
 		}
-		h.emitAudit(ctx, "ERROR", "internal error", requestID, userID)
+		h.emitAudit(c, "ERROR", "internal error", requestID, userID)
 		inc(metrics.StatusFailed)
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to update request"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to update request")
 		return
 	}
 
-	h.emitAudit(ctx, "INFO", "Friend request "+status, requestID, userID)
+	h.emitAudit(c, "INFO", "Friend request "+status, requestID, userID)
 	inc(metrics.StatusSuccess)
 	c.JSON(nethttp.StatusOK, gin.H{"status": status})
 }
@@ -189,7 +255,7 @@ func (h *FriendHandler) ListFriends(c *gin.Context) {
 
 	friends, err := h.friends.ListFriends(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to fetch friends"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to fetch friends")
 		return
 	}
 
@@ -197,7 +263,7 @@ func (h *FriendHandler) ListFriends(c *gin.Context) {
 	for _, fid := range friends {
 		friendUser, err := h.users.GetUserByID(c.Request.Context(), fid)
 		if err != nil {
-			c.JSON(nethttp.StatusBadGateway, gin.H{"error": "failed to fetch friend info"})
+			respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch friend info")
 			return
 		}
 		resp = append(resp, friendUser)
@@ -206,9 +272,15 @@ func (h *FriendHandler) ListFriends(c *gin.Context) {
 	c.JSON(nethttp.StatusOK, resp)
 }
 
-func (h *FriendHandler) emitAudit(ctx context.Context, level, text, requestID string, userID *int64) {
+func (h *FriendHandler) emitAudit(c *gin.Context, level, text, requestID string, userID *int64) {
 	if h.audit == nil {
 		return
 	}
-	h.audit.EmitAudit(ctx, level, text, requestID, userID)
+	h.audit.Emit(c.Request.Context(), telemetry.AuditEntry{
+		Level:          level,
+		Text:           text,
+		RequestID:      requestID,
+		UserID:         userID,
+		IdentitySource: identitySourceFromContext(c),
+	})
 }
@@ -8,14 +8,19 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"user-service/internal/telemetry"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"user-service/internal/middleware"
 	"user-service/internal/mocks"
 	"user-service/internal/models"
+	"user-service/internal/problem"
 	"user-service/internal/services"
 	authpb "user-service/proto/auth"
 )
@@ -56,7 +61,7 @@ func expectAuditPublish(t *testing.T, publisher *mocks.MockPublisher, requestID,
 func TestSendRequestInvalidBody(t *testing.T) {
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	requestID := "req-1"
@@ -72,13 +77,52 @@ func TestSendRequestInvalidBody(t *testing.T) {
 	mockPublisher.AssertExpectations(t)
 }
 
+func TestSendRequestIgnoresUnverifiedUserHeader(t *testing.T) {
+	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient), nil), nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/friends/request", handler.SendRequest)
+
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":2}`))
+	req.Header.Set("X-User-ID", "1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestSendRequestAuditRecordsIdentitySource(t *testing.T) {
+	mockPublisher := new(mocks.MockPublisher)
+	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
+	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/friends/request", func(c *gin.Context) {
+		c.Set("identity", &middleware.Identity{UserID: 1, Source: middleware.IdentitySourceGateway})
+		c.Set("userID", int64(1))
+		c.Next()
+	}, handler.SendRequest)
+
+	mockPublisher.On("Publish", mock.Anything, telemetry.AuditRoutingKey, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		event := args.Get(2).(telemetry.Envelope)
+		require.Equal(t, middleware.IdentitySourceGateway, event.IdentitySource)
+	}).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":"bad"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	mockPublisher.AssertExpectations(t)
+}
+
 func TestSendRequestTargetNotFound(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
+	userSvc := services.NewUserService(mockAuth, nil)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, userSvc, emitter)
+	handler := NewFriendHandler(mockFriends, userSvc, emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(2)).Return((*authpb.GetUserResponse)(nil), errors.New("missing user")).Once()
@@ -100,10 +144,10 @@ func TestSendRequestTargetNotFound(t *testing.T) {
 func TestSendRequestPendingExists(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
+	userSvc := services.NewUserService(mockAuth, nil)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, userSvc, emitter)
+	handler := NewFriendHandler(mockFriends, userSvc, emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil).Once()
@@ -127,15 +171,15 @@ func TestSendRequestPendingExists(t *testing.T) {
 func TestSendRequestAlreadyFriends(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
+	userSvc := services.NewUserService(mockAuth, nil)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, userSvc, emitter)
+	handler := NewFriendHandler(mockFriends, userSvc, emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil).Once()
 	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
-	mockFriends.On("AreFriends", mock.Anything, int64(1), int64(2)).Return(true, nil).Once()
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(true, nil).Once()
 
 	requestID := "req-3"
 	userID := int64(1)
@@ -155,15 +199,15 @@ func TestSendRequestAlreadyFriends(t *testing.T) {
 func TestSendRequestSuccess(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
+	userSvc := services.NewUserService(mockAuth, nil)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, userSvc, emitter)
+	handler := NewFriendHandler(mockFriends, userSvc, emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil).Once()
 	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
-	mockFriends.On("AreFriends", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
 	expected := &models.FriendRequest{ID: 5, FromUserID: 1, ToUserID: 2, Status: "pending"}
 	mockFriends.On("CreateRequest", mock.Anything, int64(1), int64(2)).Return(expected, nil).Once()
 
@@ -187,7 +231,7 @@ func TestSendRequestSuccess(t *testing.T) {
 }
 
 func TestAcceptRequestInvalidID(t *testing.T) {
-	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient)), nil)
+	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient), nil), nil, nil)
 	router := setupFriendsRouter(handler)
 
 	req := httptest.NewRequest(http.MethodPost, "/friends/requests/abc/accept", nil)
@@ -201,7 +245,7 @@ func TestAcceptRequestSuccess(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockFriends.On("AcceptRequest", mock.Anything, int64(7), int64(1)).Return(nil).Once()
@@ -224,7 +268,7 @@ func TestRejectRequestSuccess(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 
 	router := setupFriendsRouter(handler)
 
@@ -247,8 +291,8 @@ func TestRejectRequestSuccess(t *testing.T) {
 func TestListFriendsSuccess(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
-	handler := NewFriendHandler(mockFriends, userSvc, nil)
+	userSvc := services.NewUserService(mockAuth, nil)
+	handler := NewFriendHandler(mockFriends, userSvc, nil, nil)
 	router := setupFriendsRouter(handler)
 
 	mockFriends.On("ListFriends", mock.Anything, int64(1)).Return([]int64{2, 3}, nil).Once()
@@ -273,8 +317,8 @@ func TestListFriendsSuccess(t *testing.T) {
 func TestListIncomingSuccess(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
-	handler := NewFriendHandler(mockFriends, userSvc, nil)
+	userSvc := services.NewUserService(mockAuth, nil)
+	handler := NewFriendHandler(mockFriends, userSvc, nil, nil)
 	router := setupFriendsRouter(handler)
 
 	incoming := []models.FriendRequest{{ID: 11, FromUserID: 2}, {ID: 12, FromUserID: 3}}
@@ -301,7 +345,7 @@ func TestHandleDecisionNotFound(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockFriends.On("AcceptRequest", mock.Anything, int64(15), int64(1)).Return(sql.ErrNoRows).Once()
@@ -324,7 +368,7 @@ func TestRejectRequestNotFound(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockFriends.On("RejectRequest", mock.Anything, int64(18), int64(1)).Return(sql.ErrNoRows).Once()
@@ -347,7 +391,7 @@ func TestHandleDecisionInternalError(t *testing.T) {
 	mockFriends := new(mocks.MockFriendRepository)
 	mockPublisher := new(mocks.MockPublisher)
 	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
-	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient)), emitter)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(new(mocks.MockAuthClient), nil), emitter, nil)
 	router := setupFriendsRouter(handler)
 
 	mockFriends.On("RejectRequest", mock.Anything, int64(16), int64(1)).Return(errors.New("db down")).Once()
@@ -365,3 +409,109 @@ func TestHandleDecisionInternalError(t *testing.T) {
 	mockFriends.AssertExpectations(t)
 	mockPublisher.AssertExpectations(t)
 }
+
+func TestSendRequestHeldForReview(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	mockFriends := new(mocks.MockFriendRepository)
+	mockPublisher := new(mocks.MockPublisher)
+	emitter := telemetry.NewAuditEmitter(mockPublisher, "user-service", "local")
+	policy := services.NewRequestPolicy(mockFriends, services.RequestPolicyOptions{
+		DailyLimit:        10,
+		Window:            time.Hour,
+		MinDecisions:      2,
+		MaxRejectionRatio: 0.5,
+	})
+	handler := NewFriendHandler(mockFriends, services.NewUserService(mockAuth, nil), emitter, policy)
+	router := setupFriendsRouter(handler)
+
+	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil)
+	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil)
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(false, nil)
+	mockFriends.On("CountRequestsSince", mock.Anything, int64(1), mock.Anything).Return(int64(3), nil)
+	mockFriends.On("RequestOutcomesSince", mock.Anything, int64(1), mock.Anything).Return(int64(0), int64(3), nil)
+	mockFriends.On("CreateHeldRequest", mock.Anything, int64(1), int64(2)).Return(&models.FriendRequest{ID: 7, FromUserID: 1, ToUserID: 2, Status: "held"}, nil).Once()
+
+	userID := int64(1)
+	expectAuditPublish(t, mockPublisher, "req-held", "WARN", "Friend request to '2' held for review (rejection ratio 1.00)", &userID)
+
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":2}`))
+	req.Header.Set("X-Request-ID", "req-held")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	mockFriends.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything, mock.Anything)
+	mockFriends.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestSendRequestDailyLimit(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	mockFriends := new(mocks.MockFriendRepository)
+	policy := services.NewRequestPolicy(mockFriends, services.RequestPolicyOptions{DailyLimit: 5})
+	handler := NewFriendHandler(mockFriends, services.NewUserService(mockAuth, nil), nil, policy)
+	router := setupFriendsRouter(handler)
+
+	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil)
+	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil)
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(false, nil)
+	mockFriends.On("CountRequestsSince", mock.Anything, int64(1), mock.Anything).Return(int64(5), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":2}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	mockFriends.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendRequestByUsername(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	mockFriends := new(mocks.MockFriendRepository)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(mockAuth, nil), nil, nil)
+	router := setupFriendsRouter(handler)
+
+	mockAuth.On("GetUserByUsername", mock.Anything, "bob").Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil).Once()
+	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(false, nil).Once()
+	mockFriends.On("CreateRequest", mock.Anything, int64(1), int64(2)).Return(&models.FriendRequest{ID: 5, FromUserID: 1, ToUserID: 2, Status: "pending"}, nil).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_username":"bob"}`)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	mockAuth.AssertExpectations(t)
+	mockFriends.AssertExpectations(t)
+}
+
+func TestSendRequestByUsernameErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		matches []models.User
+		authErr error
+		status  int
+		code    string
+	}{
+		"unknown":   {authErr: status.Error(codes.NotFound, "no such user"), status: http.StatusNotFound, code: problem.CodeUserNotFound},
+		"ambiguous": {matches: []models.User{{ID: 2, Username: "bob"}, {ID: 3, Username: "Bob"}}, status: http.StatusBadRequest, code: problem.CodeAmbiguousUsername},
+		"upstream":  {authErr: status.Error(codes.Unavailable, "down"), status: http.StatusBadGateway, code: problem.CodeUpstreamFailure},
+		"self":      {matches: []models.User{{ID: 1, Username: "BOB"}}, status: http.StatusBadRequest, code: problem.CodeSelfFriendRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockAuth := new(mocks.MockAuthClient)
+			users := new(mocks.MockUserRepository)
+			handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(mockAuth, users), nil, nil)
+			router := setupFriendsRouter(handler)
+
+			users.On("GetByUsername", mock.Anything, "BOB").Return(tc.matches, nil).Once()
+			if tc.authErr != nil {
+				mockAuth.On("GetUserByUsername", mock.Anything, "BOB").Return(nil, tc.authErr).Once()
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_username":"BOB"}`)))
+
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, tc.code, decodeProblem(t, rec).Code)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/middleware"
+	"user-service/internal/models"
+	"user-service/internal/problem"
+	"user-service/internal/repositories"
+	"user-service/internal/services"
+	"user-service/internal/telemetry"
+)
+
+type InviteHandler struct {
+	invites    repositories.InviteRepository
+	tokens     *services.InviteTokens
+	audit      *telemetry.AuditEmitter
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+// NewInviteHandler creates the invite link handlers. Invites last defaultTTL
+// unless the inviter asks otherwise, and never longer than maxTTL.
+func NewInviteHandler(invites repositories.InviteRepository, tokens *services.InviteTokens, audit *telemetry.AuditEmitter, defaultTTL, maxTTL time.Duration) *InviteHandler {
+	return &InviteHandler{invites: invites, tokens: tokens, audit: audit, defaultTTL: defaultTTL, maxTTL: maxTTL}
+}
+
+type createInviteBody struct {
+	// ExpiresIn is the lifetime in seconds.
+	ExpiresIn int64 `json:"expires_in" binding:"omitempty,min=1"`
+	SingleUse bool  `json:"single_use"`
+}
+
+type inviteResponse struct {
+	models.Invite
+	Token string `json:"token"`
+}
+
+func (h *InviteHandler) Create(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	// The body is optional; an empty one takes the defaults.
+	var body createInviteBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		middleware.WriteProblem(c, bindingProblem(err, &body))
+		return
+	}
+	maxSeconds := int64(h.maxTTL / time.Second)
+	if body.ExpiresIn > maxSeconds {
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "expires_in must be at most "+strconv.FormatInt(maxSeconds, 10)+" seconds")
+		return
+	}
+	ttl := h.defaultTTL
+	if body.ExpiresIn > 0 {
+		ttl = time.Duration(body.ExpiresIn) * time.Second
+	}
+
+	// Tokens carry whole seconds, so the stored expiry must too.
+	expiresAt := time.Now().Add(ttl).UTC().Truncate(time.Second)
+	invite, err := h.invites.Create(c.Request.Context(), userID, expiresAt, body.SingleUse)
+	if err != nil {
+		h.emitAudit(c, "ERROR", "failed to create invite")
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to create invite")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Friend invite '"+invite.ID+"' created")
+	c.JSON(nethttp.StatusCreated, h.response(*invite))
+}
+
+// List returns the caller's invites, newest first, with their usage.
+func (h *InviteHandler) List(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	invites, err := h.invites.ListByInviter(c.Request.Context(), userID)
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load invites")
+		return
+	}
+
+	resp := make([]inviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		resp = append(resp, h.response(invite))
+	}
+	c.JSON(nethttp.StatusOK, gin.H{"invites": resp})
+}
+
+func (h *InviteHandler) Revoke(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+	id := c.Param("id")
+
+	err := h.invites.Revoke(c.Request.Context(), id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeInviteNotFound, "invite not found")
+		return
+	}
+	if err != nil {
+		h.emitAudit(c, "ERROR", "failed to revoke invite")
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to revoke invite")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Friend invite '"+id+"' revoked")
+	c.JSON(nethttp.StatusOK, gin.H{"status": "revoked"})
+}
+
+// Redeem befriends the caller with the inviter straight away.
+func (h *InviteHandler) Redeem(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	id, expiresAt, err := h.tokens.Parse(c.Param("token"))
+	if err != nil {
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeInviteNotFound, "invite not found")
+		return
+	}
+	if !expiresAt.After(time.Now()) {
+		respondProblem(c, nethttp.StatusGone, problem.CodeInviteExpired, "invite has expired")
+		return
+	}
+
+	invite, err := h.invites.Redeem(c.Request.Context(), id, userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		respondProblem(c, nethttp.StatusNotFound, problem.CodeInviteNotFound, "invite not found")
+		return
+	case errors.Is(err, repositories.ErrInviteExpired):
+		respondProblem(c, nethttp.StatusGone, problem.CodeInviteExpired, "invite has expired")
+		return
+	case errors.Is(err, repositories.ErrInviteRevoked):
+		respondProblem(c, nethttp.StatusGone, problem.CodeInviteRevoked, "invite has been revoked")
+		return
+	case errors.Is(err, repositories.ErrInviteUsed):
+		respondProblem(c, nethttp.StatusGone, problem.CodeInviteUsed, "invite has already been used")
+		return
+	case errors.Is(err, repositories.ErrOwnInvite):
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeSelfFriendRequest, "cannot redeem your own invite")
+		return
+	case errors.Is(err, repositories.ErrAlreadyFriends):
+		respondProblem(c, nethttp.StatusConflict, problem.CodeAlreadyFriends, "users are already friends")
+		return
+	case err != nil:
+		h.emitAudit(c, "ERROR", "failed to redeem invite")
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to redeem invite")
+		return
+	}
+
+	h.emitAudit(c, "INFO", "Friend invite '"+invite.ID+"' from '"+strconv.FormatInt(invite.InviterID, 10)+"' redeemed")
+	c.JSON(nethttp.StatusCreated, gin.H{"invite_id": invite.ID, "friend_id": invite.InviterID})
+}
+
+func (h *InviteHandler) response(invite models.Invite) inviteResponse {
+	return inviteResponse{Invite: invite, Token: h.tokens.Sign(invite.ID, invite.ExpiresAt)}
+}
+
+func (h *InviteHandler) emitAudit(c *gin.Context, level, text string) {
+	if h.audit == nil {
+		return
+	}
+	h.audit.Emit(c.Request.Context(), telemetry.AuditEntry{
+		Level:          level,
+		Text:           text,
+		RequestID:      middleware.RequestIDFromContext(c),
+		UserID:         userIDFromContext(c),
+		IdentitySource: identitySourceFromContext(c),
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/mocks"
+	"user-service/internal/models"
+	"user-service/internal/problem"
+	"user-service/internal/repositories"
+	"user-service/internal/services"
+)
+
+func setupInviteRouter(t *testing.T, invites *mocks.MockInviteRepository) (*gin.Engine, *services.InviteTokens) {
+	t.Helper()
+	tokens, err := services.NewInviteTokens("secret")
+	require.NoError(t, err)
+	handler := NewInviteHandler(invites, tokens, nil, 24*time.Hour, 48*time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	r.POST("/friends/invites", handler.Create)
+	r.DELETE("/friends/invites/:id", handler.Revoke)
+	r.POST("/friends/invites/:token/redeem", handler.Redeem)
+	return r, tokens
+}
+
+func TestCreateInvite(t *testing.T) {
+	invites := new(mocks.MockInviteRepository)
+	router, tokens := setupInviteRouter(t, invites)
+
+	created := &models.Invite{ID: "inv-1", InviterID: 1, SingleUse: true, ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second)}
+	invites.On("Create", mock.Anything, int64(1), mock.AnythingOfType("time.Time"), true).Return(created, nil).Run(func(args mock.Arguments) {
+		expiresAt := args.Get(2).(time.Time)
+		require.Zero(t, expiresAt.Nanosecond())
+		require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, 2*time.Second)
+	}).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites", bytes.NewBufferString(`{"expires_in":3600,"single_use":true}`)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp inviteResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	id, _, err := tokens.Parse(resp.Token)
+	require.NoError(t, err)
+	require.Equal(t, "inv-1", id)
+	invites.AssertExpectations(t)
+}
+
+func TestCreateInviteTooLong(t *testing.T) {
+	router, _ := setupInviteRouter(t, new(mocks.MockInviteRepository))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites", bytes.NewBufferString(`{"expires_in":172801}`)))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRedeemInvite(t *testing.T) {
+	invites := new(mocks.MockInviteRepository)
+	router, tokens := setupInviteRouter(t, invites)
+	token := tokens.Sign("inv-1", time.Now().Add(time.Hour))
+
+	invites.On("Redeem", mock.Anything, "inv-1", int64(1)).Return(&models.Invite{ID: "inv-1", InviterID: 7, Uses: 1}, nil).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites/"+token+"/redeem", nil))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.JSONEq(t, `{"invite_id":"inv-1","friend_id":7}`, rec.Body.String())
+	invites.AssertExpectations(t)
+}
+
+func TestRedeemInviteErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"unknown":         {err: sql.ErrNoRows, status: http.StatusNotFound, code: problem.CodeInviteNotFound},
+		"revoked":         {err: repositories.ErrInviteRevoked, status: http.StatusGone, code: problem.CodeInviteRevoked},
+		"used":            {err: repositories.ErrInviteUsed, status: http.StatusGone, code: problem.CodeInviteUsed},
+		"own invite":      {err: repositories.ErrOwnInvite, status: http.StatusBadRequest, code: problem.CodeSelfFriendRequest},
+		"already friends": {err: repositories.ErrAlreadyFriends, status: http.StatusConflict, code: problem.CodeAlreadyFriends},
+	} {
+		t.Run(name, func(t *testing.T) {
+			invites := new(mocks.MockInviteRepository)
+			router, tokens := setupInviteRouter(t, invites)
+			invites.On("Redeem", mock.Anything, "inv-1", int64(1)).Return(nil, tc.err).Once()
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites/"+tokens.Sign("inv-1", time.Now().Add(time.Hour))+"/redeem", nil))
+
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, tc.code, decodeProblem(t, rec).Code)
+		})
+	}
+}
+
+func TestRedeemInviteRejectsBadTokensWithoutLookup(t *testing.T) {
+	invites := new(mocks.MockInviteRepository)
+	router, tokens := setupInviteRouter(t, invites)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites/inv-1.123.forged/redeem", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites/"+tokens.Sign("inv-1", time.Now().Add(-time.Minute))+"/redeem", nil))
+	require.Equal(t, http.StatusGone, rec.Code)
+	require.Equal(t, problem.CodeInviteExpired, decodeProblem(t, rec).Code)
+
+	invites.AssertNotCalled(t, "Redeem", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRevokeInviteNotFound(t *testing.T) {
+	invites := new(mocks.MockInviteRepository)
+	router, _ := setupInviteRouter(t, invites)
+	invites.On("Revoke", mock.Anything, "inv-2", int64(1)).Return(sql.ErrNoRows).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/friends/invites/inv-2", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, problem.CodeInviteNotFound, decodeProblem(t, rec).Code)
+}
+
+func TestCreateInviteWithoutBody(t *testing.T) {
+	invites := new(mocks.MockInviteRepository)
+	router, _ := setupInviteRouter(t, invites)
+	invites.On("Create", mock.Anything, int64(1), mock.AnythingOfType("time.Time"), false).Return(&models.Invite{ID: "inv-1", InviterID: 1}, nil).Run(func(args mock.Arguments) {
+		require.WithinDuration(t, time.Now().Add(24*time.Hour), args.Get(2).(time.Time), 2*time.Second)
+	}).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/invites", nil))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	invites.AssertExpectations(t)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"user-service/internal/middleware"
+	"user-service/internal/problem"
+)
+
+// respondProblem aborts the request with a problem response.
+func respondProblem(c *gin.Context, status int, code, detail string) {
+	middleware.WriteProblem(c, problem.New(status, code, detail))
+}
+
+// bindingProblem turns a ShouldBindJSON error for body into a 400 problem
+// listing the offending fields by their JSON names.
+func bindingProblem(err error, body any) *problem.Problem {
+	p := problem.New(nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid request body")
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			message := "failed " + fe.Tag() + " validation"
+			switch fe.Tag() {
+			case "required", "required_without":
+				message = "is required"
+			case "excluded_with":
+				message = "must not be combined with " + jsonFieldName(body, fe.Param())
+			}
+			p.Errors = append(p.Errors, problem.FieldError{Field: jsonFieldName(body, fe.StructField()), Message: message})
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		p.Errors = append(p.Errors, problem.FieldError{Field: typeErr.Field, Message: "must be " + typeErr.Type.String()})
+	}
+	return p
+}
+
+func jsonFieldName(body any, structField string) string {
+	t := reflect.TypeOf(body)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if field, ok := t.FieldByName(structField); ok {
+			if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return structField
+}
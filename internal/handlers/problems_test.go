@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/mocks"
+	"user-service/internal/problem"
+	"user-service/internal/services"
+	authpb "user-service/proto/auth"
+)
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) problem.Problem {
+	t.Helper()
+	require.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return p
+}
+
+func TestProblemFieldErrors(t *testing.T) {
+	handler := NewFriendHandler(new(mocks.MockFriendRepository), services.NewUserService(new(mocks.MockAuthClient), nil), nil, nil)
+	router := setupFriendsRouter(handler)
+
+	for body, message := range map[string]string{
+		`{}`:                                   "is required",
+		`{"to_user_id":"bad"}`:                 "must be int64",
+		`{"to_user_id":2,"to_username":"bob"}`: "must not be combined with to_username",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(body))
+		req.Header.Set("X-Request-ID", "req-problem")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		p := decodeProblem(t, rec)
+		require.Equal(t, problem.CodeInvalidRequest, p.Code)
+		require.Equal(t, http.StatusBadRequest, p.Status)
+		require.Equal(t, "req-problem", p.RequestID)
+		require.Equal(t, "/friends/request", p.Instance)
+		require.Equal(t, []problem.FieldError{{Field: "to_user_id", Message: message}}, p.Errors)
+	}
+}
+
+func TestProblemCodes(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	mockFriends := new(mocks.MockFriendRepository)
+	handler := NewFriendHandler(mockFriends, services.NewUserService(mockAuth, nil), nil, nil)
+	router := setupFriendsRouter(handler)
+
+	mockAuth.On("GetUser", mock.Anything, int64(2)).Return(&authpb.GetUserResponse{Id: 2, Username: "bob"}, nil)
+	mockFriends.On("HasPendingRequest", mock.Anything, int64(1), int64(2)).Return(false, nil)
+	mockFriends.On("AreFriendsOnPrimary", mock.Anything, int64(1), int64(2)).Return(true, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":2}`)))
+	require.Equal(t, http.StatusConflict, rec.Code)
+	p := decodeProblem(t, rec)
+	require.Equal(t, problem.CodeAlreadyFriends, p.Code)
+	require.NotEmpty(t, p.RequestID)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{"to_user_id":1}`)))
+	require.Equal(t, problem.CodeSelfFriendRequest, decodeProblem(t, rec).Code)
+}
@@ -3,9 +3,15 @@ package handlers
 import (
 	nethttp "net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"user-service/internal/models"
+	"user-service/internal/problem"
 	"user-service/internal/repositories"
 	"user-service/internal/services"
 )
@@ -13,10 +19,11 @@ import (
 type UserHandler struct {
 	userService *services.UserService
 	friends     repositories.FriendRepository
+	users       repositories.UserRepository
 }
 
-func NewUserHandler(userService *services.UserService, friends repositories.FriendRepository) *UserHandler {
-	return &UserHandler{userService: userService, friends: friends}
+func NewUserHandler(userService *services.UserService, friends repositories.FriendRepository, users repositories.UserRepository) *UserHandler {
+	return &UserHandler{userService: userService, friends: friends, users: users}
 }
 
 func (h *UserHandler) GetMe(c *gin.Context) {
@@ -26,19 +33,19 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	ctx := c.Request.Context()
 	user, err := h.userService.GetUserByID(ctx, userID)
 	if err != nil {
-		c.JSON(nethttp.StatusBadGateway, gin.H{"error": "failed to fetch user"})
+		respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch user")
 		return
 	}
 
 	friends, err := h.friends.ListFriends(ctx, userID)
 	if err != nil {
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to load friends"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load friends")
 		return
 	}
 
 	incoming, err := h.friends.GetIncomingRequests(ctx, userID)
 	if err != nil {
-		c.JSON(nethttp.StatusInternalServerError, gin.H{"error": "failed to load friend requests"})
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to load friend requests")
 		return
 	}
 
@@ -46,7 +53,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	for _, fid := range friends {
 		fUser, err := h.userService.GetUserByID(ctx, fid)
 		if err != nil {
-			c.JSON(nethttp.StatusBadGateway, gin.H{"error": "failed to fetch friend info"})
+			respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch friend info")
 			return
 		}
 		friendUsers = append(friendUsers, fUser)
@@ -56,7 +63,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	for _, req := range incoming {
 		sender, err := h.userService.GetUserByID(ctx, req.FromUserID)
 		if err != nil {
-			c.JSON(nethttp.StatusBadGateway, gin.H{"error": "failed to fetch requester info"})
+			respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch requester info")
 			return
 		}
 		incomingWithUsers = append(incomingWithUsers, gin.H{
@@ -80,15 +87,73 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		c.JSON(nethttp.StatusBadRequest, gin.H{"error": "invalid user id"})
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid user id")
 		return
 	}
 
 	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(nethttp.StatusBadGateway, gin.H{"error": "user not found"})
+		if status.Code(err) == codes.NotFound {
+			respondProblem(c, nethttp.StatusNotFound, problem.CodeUserNotFound, "user not found")
+			return
+		}
+		respondProblem(c, nethttp.StatusBadGateway, problem.CodeUpstreamFailure, "failed to fetch user")
 		return
 	}
 
 	c.JSON(nethttp.StatusOK, user)
 }
+
+const (
+	maxSearchQueryLength = 64
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+)
+
+// SearchUsers finds users by username prefix or similarity, ranking those
+// sharing friends with the caller higher. Pages are addressed by offset;
+// next_offset is set while more results remain.
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	userID := c.MustGet("userID").(int64)
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+		respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "q must be 1 to 64 characters")
+		return
+	}
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid limit")
+			return
+		}
+		limit = min(parsed, maxSearchLimit)
+	}
+	offset := 0
+	if raw := c.Query("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			respondProblem(c, nethttp.StatusBadRequest, problem.CodeInvalidRequest, "invalid offset")
+			return
+		}
+		offset = parsed
+	}
+
+	users, err := h.users.Search(c.Request.Context(), userID, query, limit+1, offset)
+	if err != nil {
+		respondProblem(c, nethttp.StatusInternalServerError, problem.CodeInternal, "failed to search users")
+		return
+	}
+	if users == nil {
+		users = []models.UserSearchResult{}
+	}
+
+	resp := gin.H{}
+	if len(users) > limit {
+		users = users[:limit]
+		resp["next_offset"] = offset + limit
+	}
+	resp["users"] = users
+	c.JSON(nethttp.StatusOK, resp)
+}
@@ -5,15 +5,19 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"user-service/internal/mocks"
 	"user-service/internal/models"
+	"user-service/internal/problem"
 	"user-service/internal/services"
 	authpb "user-service/proto/auth"
 )
@@ -32,9 +36,9 @@ func setupUserRouter(userHandler *UserHandler) *gin.Engine {
 
 func TestGetUserByIDOK(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
-	userSvc := services.NewUserService(mockAuth)
+	userSvc := services.NewUserService(mockAuth, nil)
 	friendRepo := new(mocks.MockFriendRepository)
-	handler := NewUserHandler(userSvc, friendRepo)
+	handler := NewUserHandler(userSvc, friendRepo, nil)
 	router := setupUserRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(42)).Return(&authpb.GetUserResponse{Id: 42, Username: "alice"}, nil).Once()
@@ -53,8 +57,8 @@ func TestGetUserByIDOK(t *testing.T) {
 }
 
 func TestGetUserByIDInvalidID(t *testing.T) {
-	userSvc := services.NewUserService(new(mocks.MockAuthClient))
-	handler := NewUserHandler(userSvc, new(mocks.MockFriendRepository))
+	userSvc := services.NewUserService(new(mocks.MockAuthClient), nil)
+	handler := NewUserHandler(userSvc, new(mocks.MockFriendRepository), nil)
 	router := setupUserRouter(handler)
 
 	req := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
@@ -67,8 +71,8 @@ func TestGetUserByIDInvalidID(t *testing.T) {
 func TestGetMeSuccess(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
-	handler := NewUserHandler(userSvc, mockFriends)
+	userSvc := services.NewUserService(mockAuth, nil)
+	handler := NewUserHandler(userSvc, mockFriends, nil)
 	router := setupUserRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(1)).Return(&authpb.GetUserResponse{Id: 1, Username: "me"}, nil).Once()
@@ -105,8 +109,8 @@ func TestGetMeSuccess(t *testing.T) {
 func TestGetMeDependencyError(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
 	mockFriends := new(mocks.MockFriendRepository)
-	userSvc := services.NewUserService(mockAuth)
-	handler := NewUserHandler(userSvc, mockFriends)
+	userSvc := services.NewUserService(mockAuth, nil)
+	handler := NewUserHandler(userSvc, mockFriends, nil)
 	router := setupUserRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(1)).Return((*authpb.GetUserResponse)(nil), assert.AnError).Once()
@@ -121,8 +125,8 @@ func TestGetMeDependencyError(t *testing.T) {
 
 func TestGetUserByIDDependencyError(t *testing.T) {
 	mockAuth := new(mocks.MockAuthClient)
-	userSvc := services.NewUserService(mockAuth)
-	handler := NewUserHandler(userSvc, new(mocks.MockFriendRepository))
+	userSvc := services.NewUserService(mockAuth, nil)
+	handler := NewUserHandler(userSvc, new(mocks.MockFriendRepository), nil)
 	router := setupUserRouter(handler)
 
 	mockAuth.On("GetUser", mock.Anything, int64(9)).Return((*authpb.GetUserResponse)(nil), assert.AnError).Once()
@@ -134,3 +138,72 @@ func TestGetUserByIDDependencyError(t *testing.T) {
 	require.Equal(t, http.StatusBadGateway, rec.Code)
 	mockAuth.AssertExpectations(t)
 }
+
+func TestGetUserByIDNotFound(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	handler := NewUserHandler(services.NewUserService(mockAuth, nil), new(mocks.MockFriendRepository), nil)
+	router := setupUserRouter(handler)
+
+	mockAuth.On("GetUser", mock.Anything, int64(9)).Return((*authpb.GetUserResponse)(nil), status.Error(codes.NotFound, "no such user")).Once()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/9", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, problem.CodeUserNotFound, decodeProblem(t, rec).Code)
+}
+
+func setupSearchRouter(users *mocks.MockUserRepository) *gin.Engine {
+	handler := NewUserHandler(services.NewUserService(new(mocks.MockAuthClient), nil), new(mocks.MockFriendRepository), users)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	r.GET("/users/search", handler.SearchUsers)
+	return r
+}
+
+func TestSearchUsersPaginates(t *testing.T) {
+	users := new(mocks.MockUserRepository)
+	users.On("Search", mock.Anything, int64(1), "ali", 3, 4).Return([]models.UserSearchResult{
+		{ID: 2, Username: "alice", MutualFriends: 3},
+		{ID: 3, Username: "alicia"},
+		{ID: 4, Username: "malik"},
+	}, nil).Once()
+
+	rec := httptest.NewRecorder()
+	setupSearchRouter(users).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/search?q=+ali+&limit=2&offset=4", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Users      []models.UserSearchResult `json:"users"`
+		NextOffset *int                      `json:"next_offset"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Users, 2)
+	require.Equal(t, int64(3), resp.Users[0].MutualFriends)
+	require.NotNil(t, resp.NextOffset)
+	require.Equal(t, 6, *resp.NextOffset)
+	users.AssertExpectations(t)
+}
+
+func TestSearchUsersLastPage(t *testing.T) {
+	users := new(mocks.MockUserRepository)
+	users.On("Search", mock.Anything, int64(1), "bob", defaultSearchLimit+1, 0).Return(nil, nil).Once()
+
+	rec := httptest.NewRecorder()
+	setupSearchRouter(users).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/search?q=bob", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"users":[]}`, rec.Body.String())
+}
+
+func TestSearchUsersInvalidParams(t *testing.T) {
+	for _, query := range []string{"", "q=", "q=+", "q=" + strings.Repeat("a", 65), "q=a&limit=0", "q=a&offset=-1"} {
+		rec := httptest.NewRecorder()
+		setupSearchRouter(new(mocks.MockUserRepository)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/search?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
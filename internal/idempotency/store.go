@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Record is a stored idempotency key. Completed is false while the first
+// request carrying the key is still being handled.
+type Record struct {
+	RequestHash string `db:"request_hash"`
+	Completed   bool   `db:"completed"`
+	StatusCode  int    `db:"status_code"`
+	ContentType string `db:"content_type"`
+	Body        []byte `db:"response_body"`
+}
+
+// Store persists idempotency keys per user.
+type Store interface {
+	// Reserve claims key for a new request. It returns nil when the key was
+	// free (or expired) and the existing record otherwise.
+	Reserve(ctx context.Context, userID int64, key, requestHash string, ttl time.Duration) (*Record, error)
+	// Complete stores the response to replay for later duplicates.
+	Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error
+	// Release forgets a reservation so the request can be retried.
+	Release(ctx context.Context, userID int64, key string) error
+}
+
+// PostgresStore keeps keys in the idempotency_keys table.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Reserve(ctx context.Context, userID int64, key, requestHash string, ttl time.Duration) (*Record, error) {
+	var reserved int64
+	err := s.db.GetContext(ctx, &reserved, `
+INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+ON CONFLICT (user_id, key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+	response_body = NULL, created_at = NOW(), expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING user_id
+`, userID, key, requestHash, ttl.Seconds())
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var record Record
+	err = s.db.GetContext(ctx, &record, `
+SELECT request_hash, status_code IS NOT NULL AS completed, COALESCE(status_code, 0) AS status_code,
+	COALESCE(content_type, '') AS content_type, COALESCE(response_body, ''::bytea) AS response_body
+FROM idempotency_keys
+WHERE user_id=$1 AND key=$2
+`, userID, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released between the two statements; let the caller retry.
+		return s.Reserve(ctx, userID, key, requestHash, ttl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+	return &record, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+UPDATE idempotency_keys SET status_code=$3, content_type=$4, response_body=$5
+WHERE user_id=$1 AND key=$2
+`, userID, key, statusCode, contentType, body)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Release(ctx context.Context, userID int64, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id=$1 AND key=$2`, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Sweep deletes expired keys.
+func (s *PostgresStore) Sweep(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep idempotency keys: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const (
+	OutcomeSuccess   = "success"
+	OutcomeNotFound  = "not_found"
+	OutcomeForbidden = "forbidden"
+	OutcomeRejected  = "rejected"
+	OutcomeError     = "error"
+)
+
+var (
+	databaseMetricsOnce sync.Once
+
+	dbStatsMu         sync.Mutex
+	dbStatsRegistered = map[string]bool{}
+
+	dbQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of repository operations against the database, excluding event publishing",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		},
+		[]string{"operation", "outcome"},
+	)
+)
+
+func RegisterDatabaseMetrics() {
+	databaseMetricsOnce.Do(func() {
+		prometheus.MustRegister(dbQueryDuration)
+	})
+}
+
+// RegisterDBStats exports the connection pool statistics of db (open, in
+// use and idle connections, wait count and wait duration) as go_sql_*
+// metrics labelled db_name. Only the first pool registered under a name is
+// exported.
+func RegisterDBStats(db *sql.DB, name string) {
+	dbStatsMu.Lock()
+	defer dbStatsMu.Unlock()
+	if dbStatsRegistered[name] {
+		return
+	}
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+	dbStatsRegistered[name] = true
+}
+
+func ObserveDBQuery(operation, outcome string, seconds float64) {
+	RegisterDatabaseMetrics()
+	dbQueryDuration.WithLabelValues(operation, outcome).Observe(seconds)
+}
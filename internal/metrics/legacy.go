@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	legacyMetricsOnce sync.Once
+
+	legacyRouteRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "legacy_route_requests_total",
+			Help: "Total number of requests to deprecated unversioned routes",
+		},
+		[]string{"method", "path"},
+	)
+)
+
+func RegisterLegacyMetrics() {
+	legacyMetricsOnce.Do(func() {
+		prometheus.MustRegister(legacyRouteRequestsTotal)
+	})
+}
+
+func IncLegacyRoute(method, path string) {
+	RegisterLegacyMetrics()
+	legacyRouteRequestsTotal.WithLabelValues(method, path).Inc()
+}
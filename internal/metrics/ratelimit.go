@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	rateLimitMetricsOnce sync.Once
+
+	rateLimitedRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limited_requests_total",
+			Help: "Total number of requests rejected by a rate limit",
+		},
+		[]string{"route"},
+	)
+)
+
+func RegisterRateLimitMetrics() {
+	rateLimitMetricsOnce.Do(func() {
+		prometheus.MustRegister(rateLimitedRequestsTotal)
+	})
+}
+
+func IncRateLimited(route string) {
+	RegisterRateLimitMetrics()
+	rateLimitedRequestsTotal.WithLabelValues(route).Inc()
+}
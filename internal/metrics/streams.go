@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	TransportSSE       = "sse"
+	TransportWebSocket = "websocket"
+)
+
+var (
+	streamMetricsOnce sync.Once
+
+	eventStreamsActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "event_streams_active",
+			Help: "Current number of open client event streams",
+		},
+		[]string{"transport"},
+	)
+
+	eventStreamsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_streams_total",
+			Help: "Total number of client event streams opened",
+		},
+		[]string{"transport"},
+	)
+)
+
+func RegisterStreamMetrics() {
+	streamMetricsOnce.Do(func() {
+		prometheus.MustRegister(eventStreamsActive, eventStreamsTotal)
+	})
+}
+
+func StreamOpened(transport string) {
+	RegisterStreamMetrics()
+	eventStreamsActive.WithLabelValues(transport).Inc()
+	eventStreamsTotal.WithLabelValues(transport).Inc()
+}
+
+func StreamClosed(transport string) {
+	RegisterStreamMetrics()
+	eventStreamsActive.WithLabelValues(transport).Dec()
+}
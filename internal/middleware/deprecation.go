@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/metrics"
+)
+
+// Deprecated marks responses of a deprecated route per RFC 9745 and RFC 8594
+// and counts its use. successorPrefix is prepended to the request path to
+// link to the replacement; a zero sunset omits the Sunset header.
+func Deprecated(deprecatedAt, sunset time.Time, successorPrefix string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	var sunsetHeader string
+	if !sunset.IsZero() {
+		sunsetHeader = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunsetHeader != "" {
+			c.Header("Sunset", sunsetHeader)
+		}
+		if successorPrefix != "" {
+			c.Header("Link", "<"+successorPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+		}
+		metrics.IncLegacyRoute(c.Request.Method, c.FullPath())
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func serveWithGateway(t *testing.T, provider *GatewayProvider, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/friends/request", Authenticate(provider), func(c *gin.Context) {
+		identity, ok := IdentityFromContext(c)
+		require.True(t, ok)
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt64("userID"), "source": identity.Source})
+	})
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", nil)
+	req.Header = header
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func signedGatewayHeaders(secret, method, path, userID string, at time.Time) http.Header {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	h := http.Header{}
+	h.Set(GatewayUserIDHeader, userID)
+	h.Set(GatewayUsernameHeader, "alice")
+	h.Set(GatewayTimestampHeader, ts)
+	h.Set(GatewaySignatureHeader, GatewaySignature([]byte(secret), method, path, userID, "alice", "", ts))
+	return h
+}
+
+func TestGatewayProviderAcceptsSignedHeaders(t *testing.T) {
+	provider, err := NewGatewayProvider("gw-secret", time.Minute)
+	require.NoError(t, err)
+
+	rec := serveWithGateway(t, provider, signedGatewayHeaders("gw-secret", http.MethodPost, "/friends/request", "7", time.Now()))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"user_id":7,"source":"gateway"}`, rec.Body.String())
+}
+
+func TestGatewayProviderRejectsSpoofedHeaders(t *testing.T) {
+	provider, err := NewGatewayProvider("gw-secret", time.Minute)
+	require.NoError(t, err)
+
+	unsigned := http.Header{}
+	unsigned.Set(GatewayUserIDHeader, "7")
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, unsigned).Code)
+
+	wrongSecret := signedGatewayHeaders("guess", http.MethodPost, "/friends/request", "7", time.Now())
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, wrongSecret).Code)
+
+	otherRoute := signedGatewayHeaders("gw-secret", http.MethodGet, "/friends", "7", time.Now())
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, otherRoute).Code)
+
+	swappedUser := signedGatewayHeaders("gw-secret", http.MethodPost, "/friends/request", "7", time.Now())
+	swappedUser.Set(GatewayUserIDHeader, "8")
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, swappedUser).Code)
+
+	escalated := signedGatewayHeaders("gw-secret", http.MethodPost, "/friends/request", "7", time.Now())
+	escalated.Set(GatewayRoleHeader, "admin")
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, escalated).Code)
+
+	stale := signedGatewayHeaders("gw-secret", http.MethodPost, "/friends/request", "7", time.Now().Add(-2*time.Minute))
+	require.Equal(t, http.StatusUnauthorized, serveWithGateway(t, provider, stale).Code)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/idempotency"
+	"user-service/internal/logging"
+	"user-service/internal/problem"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// responseRecorder keeps a copy of everything a handler writes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyRequestHash identifies a request so a reused key can be told
+// apart from a genuine retry.
+func idempotencyRequestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Idempotency replays the stored response when a client repeats a request
+// with the same Idempotency-Key. Keys are scoped to the authenticated user;
+// reusing one with a different request is rejected with 422. Server errors
+// and 429s are not stored so the client can retry them, and neither is
+// anything when the handler panics. It must run after Authenticate.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userIDVal, ok := c.Get("userID")
+		if key == "" || !ok {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondProblem(c, http.StatusBadRequest, problem.CodeInvalidRequest, "idempotency key is too long")
+			return
+		}
+		userID := userIDVal.(int64)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, problem.CodeInvalidRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := idempotencyRequestHash(c.Request.Method, c.Request.URL.Path, body)
+
+		ctx := c.Request.Context()
+		existing, err := store.Reserve(ctx, userID, key, requestHash, ttl)
+		if err != nil {
+			logging.FromContext(ctx).Warn("idempotency check failed", "error", err)
+			respondProblem(c, http.StatusServiceUnavailable, problem.CodeUnavailable, "unable to check idempotency key")
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.RequestHash != requestHash:
+				respondProblem(c, http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused, "idempotency key was already used for a different request")
+			case !existing.Completed:
+				respondProblem(c, http.StatusConflict, problem.CodeIdempotencyKeyInUse, "a request with this idempotency key is still in progress")
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(existing.StatusCode, existing.ContentType, existing.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		// The key must be completed or released even when the client has
+		// gone away, or retries would see it in progress until it expires.
+		storeCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Release(storeCtx, userID, key); err != nil {
+				logging.FromContext(ctx).Warn("failed to release idempotency key", "error", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			return
+		}
+		if err := store.Complete(storeCtx, userID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			logging.FromContext(ctx).Warn("failed to store idempotent response", "error", err)
+			return
+		}
+		completed = true
+	}
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/idempotency"
+	"user-service/internal/problem"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*idempotency.Record
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*idempotency.Record)}
+}
+
+func (s *memoryIdempotencyStore) id(userID int64, key string) string {
+	return strconv.FormatInt(userID, 10) + "/" + key
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, userID int64, key, requestHash string, _ time.Duration) (*idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[s.id(userID, key)]; ok {
+		copied := *record
+		return &copied, nil
+	}
+	s.records[s.id(userID, key)] = &idempotency.Record{RequestHash: requestHash}
+	return nil, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, userID int64, key string, statusCode int, contentType string, body []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record := s.records[s.id(userID, key)]
+	record.Completed, record.StatusCode, record.ContentType, record.Body = true, statusCode, contentType, body
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, userID int64, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, s.id(userID, key))
+	return nil
+}
+
+func idempotentRouter(store idempotency.Store, userID int64, status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	r.POST("/friends/request", Idempotency(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(*status, gin.H{"id": *calls})
+	})
+	return r
+}
+
+func postWithKey(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	status, calls := http.StatusCreated, 0
+	r := idempotentRouter(store, 1, &status, &calls)
+
+	first := postWithKey(r, "k1", `{"to_user_id":2}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	replay := postWithKey(r, "k1", `{"to_user_id":2}`)
+	require.Equal(t, http.StatusCreated, replay.Code)
+	require.Equal(t, first.Body.String(), replay.Body.String())
+	require.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	require.Equal(t, 1, calls)
+
+	// The same key with another body is a client bug.
+	requireProblem(t, postWithKey(r, "k1", `{"to_user_id":3}`), http.StatusUnprocessableEntity, problem.CodeIdempotencyKeyReused)
+
+	// Without a key every request runs.
+	postWithKey(r, "", `{"to_user_id":2}`)
+	require.Equal(t, 2, calls)
+
+	// Keys are scoped per user.
+	other := idempotentRouter(store, 2, &status, &calls)
+	require.Equal(t, http.StatusCreated, postWithKey(other, "k1", `{"to_user_id":2}`).Code)
+	require.Equal(t, 3, calls)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	status, calls := http.StatusInternalServerError, 0
+	r := idempotentRouter(store, 1, &status, &calls)
+
+	require.Equal(t, http.StatusInternalServerError, postWithKey(r, "k1", `{}`).Code)
+	status = http.StatusCreated
+	require.Equal(t, http.StatusCreated, postWithKey(r, "k1", `{}`).Code)
+	require.Equal(t, 2, calls)
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	hash := idempotencyRequestHash(http.MethodPost, "/friends/request", []byte(`{}`))
+	_, err := store.Reserve(context.Background(), 1, "k1", hash, time.Hour)
+	require.NoError(t, err)
+
+	status, calls := http.StatusCreated, 0
+	r := idempotentRouter(store, 1, &status, &calls)
+	requireProblem(t, postWithKey(r, "k1", `{}`), http.StatusConflict, problem.CodeIdempotencyKeyInUse)
+	require.Equal(t, 0, calls)
+}
+
+func TestIdempotencyCompletesAfterClientDisconnects(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	r.POST("/friends/request", Idempotency(store, time.Hour), func(c *gin.Context) {
+		cancel()
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/friends/request", bytes.NewBufferString(`{}`)).WithContext(ctx)
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	replay := postWithKey(r, "k1", `{}`)
+	require.Equal(t, http.StatusCreated, replay.Code)
+	require.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		c.Next()
+	})
+	calls := 0
+	r.POST("/friends/request", Idempotency(store, time.Hour), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	require.Equal(t, http.StatusInternalServerError, postWithKey(r, "k1", `{}`).Code)
+	require.Equal(t, http.StatusCreated, postWithKey(r, "k1", `{}`).Code)
+	require.Equal(t, 2, calls)
+}
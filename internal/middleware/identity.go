@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/logging"
+	"user-service/internal/problem"
+)
+
+const (
+	IdentitySourceJWT     = "jwt"
+	IdentitySourceGateway = "gateway"
+
+	identityKey = "identity"
+)
+
+// Identity is the authenticated caller of a request and how it was
+// established.
+type Identity struct {
+	UserID   int64
+	Username string
+	// Role is the caller's role, e.g. "admin"; empty for regular users.
+	Role   string
+	Source string
+	// Token is the raw bearer token for JWT identities.
+	Token string
+}
+
+// IdentityProvider establishes who is calling. Implementations return an
+// *AuthError for requests that should be rejected with 401.
+type IdentityProvider interface {
+	Authenticate(c *gin.Context) (*Identity, error)
+}
+
+// AuthError is a client-facing authentication failure.
+type AuthError struct {
+	Message string
+	Err     error
+}
+
+func (e *AuthError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// Authenticate resolves the caller through provider and stores it in the gin
+// context under "identity", along with the "userID" and "username" keys the
+// handlers read.
+func Authenticate(provider IdentityProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "/metrics" || c.Request.URL.Path == "/metrics" {
+			c.Next()
+			return
+		}
+
+		identity, err := provider.Authenticate(c)
+		if err != nil {
+			var authErr *AuthError
+			if errors.As(err, &authErr) {
+				respondProblem(c, http.StatusUnauthorized, problem.CodeUnauthorized, authErr.Message)
+			} else {
+				logging.FromContext(c.Request.Context()).Error("authentication error", "error", err)
+				respondProblem(c, http.StatusInternalServerError, problem.CodeInternal, "authentication failed")
+			}
+			return
+		}
+
+		c.Set(identityKey, identity)
+		c.Set("userID", identity.UserID)
+		c.Set("username", identity.Username)
+		if identity.Token != "" {
+			c.Set("token", identity.Token)
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rejects callers whose identity does not carry role. It must run
+// after Authenticate.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := IdentityFromContext(c)
+		if !ok || identity.Role != role {
+			respondProblem(c, http.StatusForbidden, problem.CodeForbidden, "insufficient role")
+			return
+		}
+		c.Next()
+	}
+}
+
+// IdentityFromContext returns the identity stored by Authenticate.
+func IdentityFromContext(c *gin.Context) (*Identity, bool) {
+	val, ok := c.Get(identityKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := val.(*Identity)
+	return identity, ok
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"user-service/internal/logging"
+)
+
+var ErrUnknownKey = errors.New("no matching key in JWKS")
+
+// minForcedRefresh limits how often an unknown kid may trigger a refetch, so
+// tokens with random kids cannot be used to hammer the JWKS endpoint.
+const minForcedRefresh = 30 * time.Second
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet holds the public keys of a JWKS document loaded from a local file or
+// an http(s) URL and keeps them fresh in the background.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+// NewKeySet loads the JWKS at source and refreshes it every interval until ctx
+// is done. The initial load must succeed; later failures keep the last good
+// keys and are logged.
+func NewKeySet(ctx context.Context, source string, interval time.Duration) (*KeySet, error) {
+	ks := &KeySet{source: source, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := ks.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := ks.Refresh(ctx); err != nil {
+						logging.FromContext(ctx).Warn("failed to refresh JWKS", "error", err)
+					}
+				}
+			}
+		}()
+	}
+	return ks, nil
+}
+
+// Refresh reloads the key set from its source.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	data, err := k.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS from %s: %w", k.source, err)
+	}
+	keys, err := parseJWKS(ctx, data)
+	if err != nil {
+		return fmt.Errorf("failed to parse JWKS from %s: %w", k.source, err)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.lastRefresh = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(k.source, "http://") && !strings.HasPrefix(k.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(k.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// Key returns the key for kid. An empty kid is accepted only when the set
+// holds exactly one key. Unknown kids trigger a rate-limited refresh to pick
+// up rotated keys.
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if key, ok := k.lookup(kid); ok {
+		return key, nil
+	}
+
+	k.mu.RLock()
+	stale := time.Since(k.lastRefresh) >= minForcedRefresh
+	k.mu.RUnlock()
+	if stale {
+		if err := k.Refresh(ctx); err != nil {
+			logging.FromContext(ctx).Warn("failed to refresh JWKS for unknown key", "kid", kid, "error", err)
+		}
+		if key, ok := k.lookup(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+func (k *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if kid == "" {
+		if len(k.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// parseJWKS returns the signing keys of a JWKS document. Keys meant for
+// another use or of a type this service cannot verify with are skipped and
+// logged, so one unusable entry does not reject the whole document.
+func parseJWKS(ctx context.Context, data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, raw := range doc.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			logging.FromContext(ctx).Info("skipping JWKS key not meant for signing", "kid", raw.Kid, "use", raw.Use)
+			continue
+		}
+		key, err := raw.publicKey()
+		if err != nil {
+			logging.FromContext(ctx).Warn("skipping unusable JWKS key", "kid", raw.Kid, "error", err)
+			continue
+		}
+		keys[raw.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys found")
+	}
+	return keys, nil
+}
+
+func (j jwk) publicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeSegment(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeSegment(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := decodeSegment(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid coordinate length")
+		}
+		point := append([]byte{4}, append(x, y...)...)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", j.Crv)
+		}
+		x, err := decodeSegment(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
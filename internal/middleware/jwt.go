@@ -1,58 +1,159 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTAuth(secret string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if c.FullPath() == "/metrics" || c.Request.URL.Path == "/metrics" {
-			c.Next()
-			return
-		}
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" || !strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid authorization header"})
-			c.Abort()
-			return
-		}
+// JWTOptions configures token verification. At least one of Secret (HMAC) or
+// KeySet (RS*/PS*/ES*/EdDSA) must be set; with both, either kind of token is
+// accepted, which allows migrating off the shared secret.
+type JWTOptions struct {
+	Secret   string
+	KeySet   *KeySet
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
 
-		tokenString := strings.TrimSpace(authHeader[7:])
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenMalformed
-			}
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
-			return
-		}
+// TokenVerifier validates signatures and registered claims of bearer tokens.
+type TokenVerifier struct {
+	secret []byte
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+func NewTokenVerifier(opts JWTOptions) (*TokenVerifier, error) {
+	if opts.Secret == "" && opts.KeySet == nil {
+		return nil, errors.New("either a JWT secret or a JWKS key set is required")
+	}
+
+	var methods []string
+	if opts.Secret != "" {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if opts.KeySet != nil {
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA")
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &TokenVerifier{
+		secret: []byte(opts.Secret),
+		keys:   opts.KeySet,
+		parser: jwt.NewParser(parserOpts...),
+	}, nil
+}
+
+// Verify parses tokenString and returns its claims when the signature and
+// the exp/nbf/iss/aud checks pass.
+func (v *TokenVerifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	token, err := v.parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.key(ctx, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
-			c.Abort()
-			return
+func (v *TokenVerifier) key(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(v.secret) == 0 {
+			return nil, jwt.ErrTokenUnverifiable
 		}
+		return v.secret, nil
+	}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_id missing in token"})
-			c.Abort()
-			return
+	if v.keys == nil {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, err := v.keys.Key(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse a key whose type does not match the algorithm in the header.
+	switch token.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		if _, ok := key.(*rsa.PublicKey); ok {
+			return key, nil
+		}
+	case *jwt.SigningMethodECDSA:
+		if _, ok := key.(*ecdsa.PublicKey); ok {
+			return key, nil
 		}
+	case *jwt.SigningMethodEd25519:
+		if _, ok := key.(ed25519.PublicKey); ok {
+			return key, nil
+		}
+	}
+	return nil, jwt.ErrTokenSignatureInvalid
+}
+
+// JWTProvider authenticates requests carrying a bearer token.
+type JWTProvider struct {
+	verifier *TokenVerifier
+}
 
-		username, _ := claims["username"].(string)
+func NewJWTProvider(verifier *TokenVerifier) *JWTProvider {
+	return &JWTProvider{verifier: verifier}
+}
+
+func (p *JWTProvider) Authenticate(c *gin.Context) (*Identity, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" || !strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
+		return nil, &AuthError{Message: "missing or invalid authorization header"}
+	}
+
+	tokenString := strings.TrimSpace(authHeader[7:])
+
+	claims, err := p.verifier.Verify(c.Request.Context(), tokenString)
+	if err != nil {
+		return nil, &AuthError{Message: "invalid token", Err: err}
+	}
 
-		c.Set("userID", int64(userID))
-		c.Set("username", username)
-		c.Next()
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, &AuthError{Message: "user_id missing in token"}
 	}
+
+	username, _ := claims["username"].(string)
+	role, _ := claims["role"].(string)
+
+	return &Identity{
+		UserID:   int64(userID),
+		Username: username,
+		Role:     role,
+		Source:   IdentitySourceJWT,
+		Token:    tokenString,
+	}, nil
+}
+
+// JWTAuth authenticates requests with bearer tokens checked by verifier.
+func JWTAuth(verifier *TokenVerifier) gin.HandlerFunc {
+	return Authenticate(NewJWTProvider(verifier))
 }
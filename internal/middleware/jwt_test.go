@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/problem"
+)
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func publicJWK(t *testing.T, kid string, key crypto.PublicKey) map[string]string {
+	t.Helper()
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kid": kid, "kty": "RSA", "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		raw, err := k.Bytes()
+		require.NoError(t, err)
+		size := (len(raw) - 1) / 2
+		return map[string]string{"kid": kid, "kty": "EC", "crv": "P-256", "x": b64(raw[1 : 1+size]), "y": b64(raw[1+size:])}
+	case ed25519.PublicKey:
+		return map[string]string{"kid": kid, "kty": "OKP", "crv": "Ed25519", "x": b64(k)}
+	}
+	t.Fatalf("unsupported key %T", key)
+	return nil
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id":  float64(7),
+		"username": "alice",
+		"iss":      "auth-service",
+		"aud":      "user-service",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func serveWithAuth(verifier *TokenVerifier, token string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", JWTAuth(verifier), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt64("userID"), "username": c.GetString("username")})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJWTAuthHMAC(t *testing.T) {
+	verifier, err := NewTokenVerifier(JWTOptions{Secret: "secret"})
+	require.NoError(t, err)
+
+	rec := serveWithAuth(verifier, signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims()))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"user_id":7,"username":"alice"}`, rec.Body.String())
+
+	rec = serveWithAuth(verifier, signToken(t, jwt.SigningMethodHS256, "", []byte("other"), validClaims()))
+	requireProblem(t, rec, http.StatusUnauthorized, problem.CodeUnauthorized)
+}
+
+func TestJWTAuthJWKSAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	path := writeJWKS(t,
+		publicJWK(t, "rsa-1", &rsaKey.PublicKey),
+		publicJWK(t, "ec-1", &ecKey.PublicKey),
+		publicJWK(t, "ed-1", edPub),
+	)
+	keys, err := NewKeySet(context.Background(), path, 0)
+	require.NoError(t, err)
+	verifier, err := NewTokenVerifier(JWTOptions{KeySet: keys, Issuer: "auth-service", Audience: "user-service"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    string
+		key    any
+		code   int
+	}{
+		{"RS256", jwt.SigningMethodRS256, "rsa-1", rsaKey, http.StatusOK},
+		{"ES256", jwt.SigningMethodES256, "ec-1", ecKey, http.StatusOK},
+		{"EdDSA", jwt.SigningMethodEdDSA, "ed-1", edPriv, http.StatusOK},
+		{"unknown kid", jwt.SigningMethodRS256, "rsa-2", rsaKey, http.StatusUnauthorized},
+		{"kid for other key type", jwt.SigningMethodES256, "rsa-1", ecKey, http.StatusUnauthorized},
+		{"HMAC without secret", jwt.SigningMethodHS256, "", []byte("secret"), http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serveWithAuth(verifier, signToken(t, tc.method, tc.kid, tc.key, validClaims()))
+			require.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestKeySetSkipsUnusableKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := writeJWKS(t,
+		map[string]string{"kid": "enc-1", "kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		map[string]string{"kid": "oct-1", "kty": "oct", "k": "c2VjcmV0"},
+		map[string]string{"kid": "ec-bad", "kty": "EC", "crv": "secp256k1", "x": "AA", "y": "AA"},
+		publicJWK(t, "ec-1", &ecKey.PublicKey),
+	)
+	keys, err := NewKeySet(context.Background(), path, 0)
+	require.NoError(t, err)
+
+	key, err := keys.Key(context.Background(), "")
+	require.NoError(t, err)
+	require.True(t, ecKey.PublicKey.Equal(key))
+	_, err = keys.Key(context.Background(), "oct-1")
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = NewKeySet(context.Background(), writeJWKS(t, map[string]string{"kid": "oct-1", "kty": "oct"}), 0)
+	require.Error(t, err)
+}
+
+func TestJWTAuthClaimValidation(t *testing.T) {
+	verifier, err := NewTokenVerifier(JWTOptions{Secret: "secret", Issuer: "auth-service", Audience: "user-service", Leeway: time.Minute})
+	require.NoError(t, err)
+
+	mutate := func(fn func(jwt.MapClaims)) string {
+		claims := validClaims()
+		fn(claims)
+		return signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), claims)
+	}
+
+	require.Equal(t, http.StatusUnauthorized, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { c["iss"] = "someone-else" })).Code)
+	require.Equal(t, http.StatusUnauthorized, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { c["aud"] = "chat-service" })).Code)
+	require.Equal(t, http.StatusUnauthorized, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { delete(c, "exp") })).Code)
+	require.Equal(t, http.StatusUnauthorized, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() })).Code)
+	require.Equal(t, http.StatusOK, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() })).Code)
+	require.Equal(t, http.StatusUnauthorized, serveWithAuth(verifier, mutate(func(c jwt.MapClaims) { delete(c, "user_id") })).Code)
+}
+
+func TestKeySetURLPicksUpRotatedKey(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var rotated atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys := []map[string]string{publicJWK(t, "old", &oldKey.PublicKey)}
+		if rotated.Load() {
+			keys = append(keys, publicJWK(t, "new", &newKey.PublicKey))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer srv.Close()
+
+	keys, err := NewKeySet(context.Background(), srv.URL, 0)
+	require.NoError(t, err)
+
+	_, err = keys.Key(context.Background(), "")
+	require.NoError(t, err)
+
+	rotated.Store(true)
+	keys.lastRefresh = time.Time{}
+	key, err := keys.Key(context.Background(), "new")
+	require.NoError(t, err)
+	require.True(t, newKey.PublicKey.Equal(key))
+
+	// With two keys an unnamed kid is ambiguous.
+	_, err = keys.Key(context.Background(), "")
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestRequireRole(t *testing.T) {
+	verifier, err := NewTokenVerifier(JWTOptions{Secret: "secret"})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", JWTAuth(verifier), RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	serve := func(role string) int {
+		claims := validClaims()
+		if role != "" {
+			claims["role"] = role
+		}
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), claims))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusNoContent, serve("admin"))
+	require.Equal(t, http.StatusForbidden, serve("user"))
+	require.Equal(t, http.StatusForbidden, serve(""))
+}
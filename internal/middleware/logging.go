@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"user-service/internal/logging"
+)
+
+// credentialParams name route parameters that carry bearer credentials, such
+// as invite tokens, and must not reach logs or traces.
+var credentialParams = []string{"token"}
+
+// loggablePath returns the request path, or the route pattern when the path
+// holds a credential.
+func loggablePath(c *gin.Context) string {
+	for _, name := range credentialParams {
+		if _, ok := c.Params.Get(name); ok {
+			return c.FullPath()
+		}
+	}
+	return c.Request.URL.Path
+}
+
+// RedactTracePath replaces the url.path attribute of the request span with
+// the route pattern when the path holds a credential. It must run right
+// after the tracing middleware.
+func RedactTracePath() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if path := loggablePath(c); path != c.Request.URL.Path {
+			trace.SpanFromContext(c.Request.Context()).SetAttributes(semconv.URLPath(path))
+		}
+		c.Next()
+	}
+}
+
+// AccessLog writes one structured log line per request. It must run after
+// RequestID so the line carries the request ID.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []any{
+			"method", c.Request.Method,
+			"route", route,
+			"path", loggablePath(c),
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if userID, ok := c.Get("userID"); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// Recovery turns a panicking handler into a 500 and logs the panic with its
+// stack trace.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logging.FromContext(c.Request.Context()).Error("panic recovered",
+					"panic", rec,
+					"stack", string(debug.Stack()),
+				)
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"user-service/internal/problem"
+)
+
+// WriteProblem aborts the request with p, tagged with the request ID.
+func WriteProblem(c *gin.Context, p *problem.Problem) {
+	p.RequestID = RequestIDFromContext(c)
+	problem.Write(c, p)
+}
+
+func respondProblem(c *gin.Context, status int, code, detail string) {
+	WriteProblem(c, problem.New(status, code, detail))
+}
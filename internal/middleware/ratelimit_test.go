@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/problem"
+	"user-service/internal/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("db down")
+}
+
+func rateLimitedRouter(limiter *RateLimiter, userID int64, trustedProxies ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		panic(err)
+	}
+	r.Use(func(c *gin.Context) {
+		if userID != 0 {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	r.POST("/friends/request", limiter.Limit("friend_request", ratelimit.Limit{Rate: 0.1, Burst: 1}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func TestRateLimitReturnsRetryAfter(t *testing.T) {
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore())
+	alice := rateLimitedRouter(limiter, 1)
+	bob := rateLimitedRouter(limiter, 2)
+
+	serve := func(r *gin.Engine) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", nil))
+		return rec
+	}
+
+	require.Equal(t, http.StatusNoContent, serve(alice).Code)
+	rec := serve(alice)
+	requireProblem(t, rec, http.StatusTooManyRequests, problem.CodeRateLimited)
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	// Each user has their own bucket.
+	require.Equal(t, http.StatusNoContent, serve(bob).Code)
+}
+
+func TestRateLimitFallsBackToClientIP(t *testing.T) {
+	r := rateLimitedRouter(NewRateLimiter(ratelimit.NewMemoryStore()), 0)
+
+	serve := func(addr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/friends/request", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.1:1234"))
+	require.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:5678"))
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.2:1234"))
+}
+
+func TestRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	serve := func(r *gin.Engine, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/friends/request", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Without trusted proxies a spoofed header does not buy a fresh bucket.
+	r := rateLimitedRouter(NewRateLimiter(ratelimit.NewMemoryStore()), 0)
+	require.Equal(t, http.StatusNoContent, serve(r, "203.0.113.1"))
+	require.Equal(t, http.StatusTooManyRequests, serve(r, "203.0.113.2"))
+
+	// Behind a trusted proxy each forwarded client has its own bucket.
+	r = rateLimitedRouter(NewRateLimiter(ratelimit.NewMemoryStore()), 0, "10.0.0.0/8")
+	require.Equal(t, http.StatusNoContent, serve(r, "203.0.113.1"))
+	require.Equal(t, http.StatusNoContent, serve(r, "203.0.113.2"))
+	require.Equal(t, http.StatusTooManyRequests, serve(r, "203.0.113.1"))
+}
+
+func TestRateLimitAllowsWhenStoreFails(t *testing.T) {
+	r := rateLimitedRouter(NewRateLimiter(failingStore{}), 1)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// A nil limiter disables limiting.
+	r = rateLimitedRouter(nil, 1)
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/friends/request", nil))
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	}
+}
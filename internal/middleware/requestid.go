@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"user-service/internal/logging"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey       = "requestID"
+	maxRequestIDLength = 128
+)
+
+// RequestID assigns every request an ID, taken from X-Request-ID when the
+// caller sent a usable one, echoes it in the response, and stores a logger
+// tagged with it in the request context. When a tracing middleware runs
+// first the logger also carries the trace ID.
+func RequestID(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := RequestIDFromContext(c)
+		c.Header(RequestIDHeader, requestID)
+
+		logger := base.With("request_id", requestID)
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			logger = logger.With("trace_id", span.TraceID().String())
+		}
+		ctx := logging.WithLogger(c.Request.Context(), logger)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequestIDFromContext returns the ID assigned by RequestID. Outside that
+// middleware it falls back to the header, or a new UUID, and remembers the
+// choice so every caller in the request sees the same ID.
+func RequestIDFromContext(c *gin.Context) string {
+	if requestID := c.GetString(requestIDKey); requestID != "" {
+		return requestID
+	}
+	requestID := c.GetHeader(RequestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+	c.Set(requestIDKey, requestID)
+	return requestID
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
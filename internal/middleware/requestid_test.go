@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"user-service/internal/logging"
+)
+
+func loggedRouter(buf *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger, _ := logging.New(buf, "info")
+	r := gin.New()
+	r.Use(RequestID(logger), AccessLog(), Recovery())
+	r.GET("/friends/:id", func(c *gin.Context) {
+		c.Set("userID", int64(7))
+		c.String(http.StatusOK, RequestIDFromContext(c))
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestRequestIDEchoesClientID(t *testing.T) {
+	r := loggedRouter(&bytes.Buffer{})
+
+	req := httptest.NewRequest(http.MethodGet, "/friends/1", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+	require.Equal(t, "abc-123", w.Body.String())
+}
+
+func TestRequestIDReplacesMissingOrInvalidID(t *testing.T) {
+	r := loggedRouter(&bytes.Buffer{})
+
+	for _, header := range []string{"", "has space", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/friends/1", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		got := w.Header().Get(RequestIDHeader)
+		require.NotEmpty(t, got)
+		require.NotEqual(t, header, got)
+		require.Equal(t, got, w.Body.String())
+	}
+}
+
+func TestAccessLogFields(t *testing.T) {
+	var buf bytes.Buffer
+	r := loggedRouter(&buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/friends/1", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "http request", line["msg"])
+	require.Equal(t, "abc-123", line["request_id"])
+	require.Equal(t, "/friends/:id", line["route"])
+	require.Equal(t, float64(http.StatusOK), line["status"])
+	require.Equal(t, float64(7), line["user_id"])
+	require.Contains(t, line, "latency_ms")
+}
+
+func TestRequestIDLogsTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, "info")
+	provider := sdktrace.NewTracerProvider()
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx, span := provider.Tracer("test").Start(c.Request.Context(), "request")
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	})
+	r.Use(RequestID(logger), AccessLog())
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Len(t, line["trace_id"], 32)
+}
+
+func TestRecoveryLogsPanic(t *testing.T) {
+	var buf bytes.Buffer
+	r := loggedRouter(&buf)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Contains(t, buf.String(), `"msg":"panic recovered"`)
+	require.Contains(t, buf.String(), `"panic":"boom"`)
+}
+
+func TestInviteTokensStayOutOfLogsAndTraces(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, "info")
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+
+	r := gin.New()
+	r.Use(otelgin.Middleware("test", otelgin.WithTracerProvider(provider)), RedactTracePath(), RequestID(logger), AccessLog())
+	var token string
+	r.POST("/friends/invites/:token/redeem", func(c *gin.Context) {
+		token = c.Param("token")
+		c.Status(http.StatusCreated)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/friends/invites/inv.123.secret/redeem", nil))
+
+	require.Equal(t, "inv.123.secret", token)
+	require.NotContains(t, buf.String(), "secret")
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "/friends/invites/:token/redeem", line["path"])
+
+	require.Len(t, spans.Ended(), 1)
+	for _, attr := range spans.Ended()[0].Attributes() {
+		require.NotContains(t, attr.Value.Emit(), "secret", string(attr.Key))
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-service/internal/logging"
+	"user-service/internal/problem"
+	authpb "user-service/proto/auth"
+)
+
+const (
+	RevocationFailOpen   = "fail-open"
+	RevocationFailClosed = "fail-closed"
+
+	// maxRevocationCacheEntries bounds memory; expired entries are swept
+	// once the cache reaches this size.
+	maxRevocationCacheEntries = 10000
+)
+
+var (
+	ErrTokenRevoked    = errors.New("token has been revoked")
+	ErrAuthUnavailable = errors.New("auth service unavailable")
+)
+
+// TokenValidator is the auth-service call that reports whether a token is
+// still active.
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*authpb.ValidateTokenResponse, error)
+}
+
+type RevocationOptions struct {
+	// CacheTTL is how long a positive answer is reused; a revoked token can
+	// stay usable for at most this long.
+	CacheTTL time.Duration
+	// Timeout bounds each ValidateToken call.
+	Timeout time.Duration
+	// Policy is RevocationFailOpen or RevocationFailClosed and decides what
+	// happens when auth-service cannot be reached.
+	Policy string
+}
+
+// RevocationChecker asks auth-service whether tokens were revoked, caching
+// positive results by token hash.
+type RevocationChecker struct {
+	validator TokenValidator
+	opts      RevocationOptions
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]time.Time
+}
+
+func NewRevocationChecker(validator TokenValidator, opts RevocationOptions) (*RevocationChecker, error) {
+	if opts.Policy != RevocationFailOpen && opts.Policy != RevocationFailClosed {
+		return nil, fmt.Errorf("unknown revocation policy %q", opts.Policy)
+	}
+	return &RevocationChecker{
+		validator: validator,
+		opts:      opts,
+		cache:     make(map[[sha256.Size]byte]time.Time),
+	}, nil
+}
+
+// Check returns nil when token is active for userID, ErrTokenRevoked when
+// auth-service rejects it, and ErrAuthUnavailable when auth-service cannot
+// answer under the fail-closed policy. Only an invalid token or an
+// Unauthenticated, PermissionDenied or NotFound status is a rejection; any
+// other error is auth-service failing and goes through the policy.
+func (r *RevocationChecker) Check(ctx context.Context, token string, userID int64) error {
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+
+	r.mu.Lock()
+	expires, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok && now.Before(expires) {
+		return nil
+	}
+
+	callCtx := ctx
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
+
+	resp, err := r.validator.ValidateToken(callCtx, token)
+	if err != nil {
+		if isTokenRejection(err) {
+			return fmt.Errorf("%w: %v", ErrTokenRevoked, err)
+		}
+		if r.opts.Policy == RevocationFailOpen {
+			logging.FromContext(ctx).Warn("token revocation check skipped", "error", err)
+			return nil
+		}
+		return fmt.Errorf("%w: %v", ErrAuthUnavailable, err)
+	}
+	if !resp.GetValid() || (resp.GetUserId() != 0 && resp.GetUserId() != userID) {
+		return ErrTokenRevoked
+	}
+
+	if r.opts.CacheTTL > 0 {
+		r.mu.Lock()
+		if len(r.cache) >= maxRevocationCacheEntries {
+			for k, exp := range r.cache {
+				if !now.Before(exp) {
+					delete(r.cache, k)
+				}
+			}
+		}
+		if len(r.cache) < maxRevocationCacheEntries {
+			r.cache[key] = now.Add(r.opts.CacheTTL)
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// isTokenRejection reports whether a ValidateToken error is auth-service's
+// answer that the token is no good, as opposed to auth-service failing.
+func isTokenRejection(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.NotFound:
+		return true
+	}
+	return false
+}
+
+// RevocationCheck rejects tokens that auth-service no longer considers valid.
+// It must run after JWTAuth, which establishes the user ID.
+func RevocationCheck(checker *RevocationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetString("token")
+		userID, ok := c.Get("userID")
+		if token == "" || !ok {
+			c.Next()
+			return
+		}
+
+		err := checker.Check(c.Request.Context(), token, userID.(int64))
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, ErrAuthUnavailable):
+			logging.FromContext(c.Request.Context()).Warn("rejecting request, token revocation check failed", "error", err)
+			respondProblem(c, http.StatusServiceUnavailable, problem.CodeUnavailable, "unable to verify token")
+		default:
+			respondProblem(c, http.StatusUnauthorized, problem.CodeTokenRevoked, "token has been revoked")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-service/internal/mocks"
+	"user-service/internal/problem"
+	authpb "user-service/proto/auth"
+)
+
+func serveWithRevocation(t *testing.T, checker *RevocationChecker, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	verifier, err := NewTokenVerifier(JWTOptions{Secret: "secret"})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", JWTAuth(verifier), RevocationCheck(checker), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRevocationCheckCachesValidTokens(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	checker, err := NewRevocationChecker(mockAuth, RevocationOptions{CacheTTL: time.Minute, Policy: RevocationFailClosed})
+	require.NoError(t, err)
+
+	token := signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims())
+	mockAuth.On("ValidateToken", mock.Anything, token).Return(&authpb.ValidateTokenResponse{Valid: true, UserId: 7}, nil).Once()
+
+	require.Equal(t, http.StatusNoContent, serveWithRevocation(t, checker, token).Code)
+	require.Equal(t, http.StatusNoContent, serveWithRevocation(t, checker, token).Code)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestRevocationCheckRejectsRevokedToken(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	checker, err := NewRevocationChecker(mockAuth, RevocationOptions{CacheTTL: time.Minute, Policy: RevocationFailClosed})
+	require.NoError(t, err)
+
+	token := signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims())
+	mockAuth.On("ValidateToken", mock.Anything, token).Return(&authpb.ValidateTokenResponse{Valid: false}, nil).Twice()
+
+	requireProblem(t, serveWithRevocation(t, checker, token), http.StatusUnauthorized, problem.CodeTokenRevoked)
+	// Negative answers are never cached.
+	require.Equal(t, http.StatusUnauthorized, serveWithRevocation(t, checker, token).Code)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestRevocationCheckUserMismatch(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	checker, err := NewRevocationChecker(mockAuth, RevocationOptions{Policy: RevocationFailClosed})
+	require.NoError(t, err)
+
+	mockAuth.On("ValidateToken", mock.Anything, "tok").Return(&authpb.ValidateTokenResponse{Valid: true, UserId: 8}, nil).Once()
+	require.ErrorIs(t, checker.Check(context.Background(), "tok", 7), ErrTokenRevoked)
+}
+
+func TestRevocationCheckPolicies(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	token := signToken(t, jwt.SigningMethodHS256, "", []byte("secret"), validClaims())
+
+	mockAuth := new(mocks.MockAuthClient)
+	mockAuth.On("ValidateToken", mock.Anything, token).Return((*authpb.ValidateTokenResponse)(nil), unavailable)
+
+	closed, err := NewRevocationChecker(mockAuth, RevocationOptions{Policy: RevocationFailClosed})
+	require.NoError(t, err)
+	requireProblem(t, serveWithRevocation(t, closed, token), http.StatusServiceUnavailable, problem.CodeUnavailable)
+
+	open, err := NewRevocationChecker(mockAuth, RevocationOptions{Policy: RevocationFailOpen})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, serveWithRevocation(t, open, token).Code)
+
+	_, err = NewRevocationChecker(mockAuth, RevocationOptions{Policy: "sometimes"})
+	require.Error(t, err)
+}
+
+func TestRevocationCheckRejectsOnlyOnAnswers(t *testing.T) {
+	mockAuth := new(mocks.MockAuthClient)
+	mockAuth.On("ValidateToken", mock.Anything, "bad").Return((*authpb.ValidateTokenResponse)(nil), status.Error(codes.Unauthenticated, "token revoked"))
+	mockAuth.On("ValidateToken", mock.Anything, "slow").Return((*authpb.ValidateTokenResponse)(nil), status.Error(codes.DeadlineExceeded, "deadline exceeded"))
+	mockAuth.On("ValidateToken", mock.Anything, "broken").Return((*authpb.ValidateTokenResponse)(nil), status.Error(codes.Internal, "database error"))
+
+	open, err := NewRevocationChecker(mockAuth, RevocationOptions{Policy: RevocationFailOpen})
+	require.NoError(t, err)
+	require.ErrorIs(t, open.Check(context.Background(), "bad", 7), ErrTokenRevoked)
+	require.NoError(t, open.Check(context.Background(), "slow", 7))
+	require.NoError(t, open.Check(context.Background(), "broken", 7))
+
+	closed, err := NewRevocationChecker(mockAuth, RevocationOptions{Policy: RevocationFailClosed})
+	require.NoError(t, err)
+	require.ErrorIs(t, closed.Check(context.Background(), "bad", 7), ErrTokenRevoked)
+	require.ErrorIs(t, closed.Check(context.Background(), "slow", 7), ErrAuthUnavailable)
+	require.ErrorIs(t, closed.Check(context.Background(), "broken", 7), ErrAuthUnavailable)
+}
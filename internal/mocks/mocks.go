@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -25,6 +26,15 @@ func (m *MockAuthClient) GetUser(ctx context.Context, userID int64) (*authpb.Get
 	return resp, args.Error(1)
 }
 
+func (m *MockAuthClient) GetUserByUsername(ctx context.Context, username string) (*authpb.GetUserResponse, error) {
+	args := m.Called(ctx, username)
+	var resp *authpb.GetUserResponse
+	if val := args.Get(0); val != nil {
+		resp = val.(*authpb.GetUserResponse)
+	}
+	return resp, args.Error(1)
+}
+
 func (m *MockAuthClient) ValidateToken(ctx context.Context, token string) (*authpb.ValidateTokenResponse, error) {
 	args := m.Called(ctx, token)
 	var resp *authpb.ValidateTokenResponse
@@ -57,6 +67,15 @@ func (m *MockFriendRepository) GetIncomingRequests(ctx context.Context, userID i
 	return reqs, args.Error(1)
 }
 
+func (m *MockFriendRepository) GetOutgoingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	args := m.Called(ctx, userID)
+	var reqs []models.FriendRequest
+	if val := args.Get(0); val != nil {
+		reqs = val.([]models.FriendRequest)
+	}
+	return reqs, args.Error(1)
+}
+
 func (m *MockFriendRepository) AcceptRequest(ctx context.Context, requestID, userID int64) error {
 	args := m.Called(ctx, requestID, userID)
 	return args.Error(0)
@@ -86,22 +105,226 @@ func (m *MockFriendRepository) AreFriends(ctx context.Context, userID, otherID i
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockFriendRepository) AreFriendsOnPrimary(ctx context.Context, userID, otherID int64) (bool, error) {
+	args := m.Called(ctx, userID, otherID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFriendRepository) CountFriends(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFriendRepository) FilterFriends(ctx context.Context, userID int64, candidateIDs []int64) ([]int64, error) {
+	args := m.Called(ctx, userID, candidateIDs)
+	var friends []int64
+	if val := args.Get(0); val != nil {
+		friends = val.([]int64)
+	}
+	return friends, args.Error(1)
+}
+
+func (m *MockFriendRepository) RemoveFriendship(ctx context.Context, userID, friendID int64) (bool, error) {
+	args := m.Called(ctx, userID, friendID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFriendRepository) PurgePendingRequests(ctx context.Context, fromUserID int64) (int64, error) {
+	args := m.Called(ctx, fromUserID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFriendRepository) CountRequestsSince(ctx context.Context, fromUserID int64, since time.Time) (int64, error) {
+	args := m.Called(ctx, fromUserID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFriendRepository) RequestOutcomesSince(ctx context.Context, fromUserID int64, since time.Time) (int64, int64, error) {
+	args := m.Called(ctx, fromUserID, since)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockFriendRepository) CreateHeldRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error) {
+	args := m.Called(ctx, fromUserID, toUserID)
+	var req *models.FriendRequest
+	if val := args.Get(0); val != nil {
+		req = val.(*models.FriendRequest)
+	}
+	return req, args.Error(1)
+}
+
+func (m *MockFriendRepository) ListHeldRequests(ctx context.Context, limit int) ([]models.FriendRequest, error) {
+	args := m.Called(ctx, limit)
+	var reqs []models.FriendRequest
+	if val := args.Get(0); val != nil {
+		reqs = val.([]models.FriendRequest)
+	}
+	return reqs, args.Error(1)
+}
+
+func (m *MockFriendRepository) ReviewHeldRequest(ctx context.Context, requestID int64, release bool) (*models.FriendRequest, error) {
+	args := m.Called(ctx, requestID, release)
+	var req *models.FriendRequest
+	if val := args.Get(0); val != nil {
+		req = val.(*models.FriendRequest)
+	}
+	return req, args.Error(1)
+}
+
+func (m *MockFriendRepository) DeleteUserData(ctx context.Context, userID int64) (models.DeletedUserData, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.DeletedUserData), args.Error(1)
+}
+
+func (m *MockFriendRepository) ListAllRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	args := m.Called(ctx, userID)
+	var reqs []models.FriendRequest
+	if val := args.Get(0); val != nil {
+		reqs = val.([]models.FriendRequest)
+	}
+	return reqs, args.Error(1)
+}
+
+func (m *MockFriendRepository) CountAllRequests(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Compile-time assertions
 var _ interface {
 	GetUser(context.Context, int64) (*authpb.GetUserResponse, error)
+	GetUserByUsername(context.Context, string) (*authpb.GetUserResponse, error)
 	ValidateToken(context.Context, string) (*authpb.ValidateTokenResponse, error)
 } = (*MockAuthClient)(nil)
 
 var _ interface {
 	CreateRequest(context.Context, int64, int64) (*models.FriendRequest, error)
 	GetIncomingRequests(context.Context, int64) ([]models.FriendRequest, error)
+	GetOutgoingRequests(context.Context, int64) ([]models.FriendRequest, error)
 	AcceptRequest(context.Context, int64, int64) error
 	RejectRequest(context.Context, int64, int64) error
 	ListFriends(context.Context, int64) ([]int64, error)
 	HasPendingRequest(context.Context, int64, int64) (bool, error)
 	AreFriends(context.Context, int64, int64) (bool, error)
+	AreFriendsOnPrimary(context.Context, int64, int64) (bool, error)
+	CountFriends(context.Context, int64) (int64, error)
+	FilterFriends(context.Context, int64, []int64) ([]int64, error)
+	RemoveFriendship(context.Context, int64, int64) (bool, error)
+	PurgePendingRequests(context.Context, int64) (int64, error)
+	CountRequestsSince(context.Context, int64, time.Time) (int64, error)
+	RequestOutcomesSince(context.Context, int64, time.Time) (int64, int64, error)
+	CreateHeldRequest(context.Context, int64, int64) (*models.FriendRequest, error)
+	ListHeldRequests(context.Context, int) ([]models.FriendRequest, error)
+	ReviewHeldRequest(context.Context, int64, bool) (*models.FriendRequest, error)
+	DeleteUserData(context.Context, int64) (models.DeletedUserData, error)
+	ListAllRequests(context.Context, int64) ([]models.FriendRequest, error)
+	CountAllRequests(context.Context, int64) (int64, error)
 } = (*MockFriendRepository)(nil)
 
+// MockUserRepository mocks the local user projection.
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	args := m.Called(ctx, id)
+	var user *models.User
+	if val := args.Get(0); val != nil {
+		user = val.(*models.User)
+	}
+	return user, args.Error(1)
+}
+
+func (m *MockUserRepository) GetByUsername(ctx context.Context, username string) ([]models.User, error) {
+	args := m.Called(ctx, username)
+	var users []models.User
+	if val := args.Get(0); val != nil {
+		users = val.([]models.User)
+	}
+	return users, args.Error(1)
+}
+
+func (m *MockUserRepository) Upsert(ctx context.Context, user models.User, syncedAt time.Time) error {
+	args := m.Called(ctx, user, syncedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkDeleted(ctx context.Context, id int64, syncedAt time.Time) error {
+	args := m.Called(ctx, id, syncedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ReferencedUserIDs(ctx context.Context) ([]int64, error) {
+	args := m.Called(ctx)
+	var ids []int64
+	if val := args.Get(0); val != nil {
+		ids = val.([]int64)
+	}
+	return ids, args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, viewerID int64, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	args := m.Called(ctx, viewerID, query, limit, offset)
+	var users []models.UserSearchResult
+	if val := args.Get(0); val != nil {
+		users = val.([]models.UserSearchResult)
+	}
+	return users, args.Error(1)
+}
+
+var _ interface {
+	GetByID(context.Context, int64) (*models.User, error)
+	GetByUsername(context.Context, string) ([]models.User, error)
+	Upsert(context.Context, models.User, time.Time) error
+	MarkDeleted(context.Context, int64, time.Time) error
+	ReferencedUserIDs(context.Context) ([]int64, error)
+	Search(context.Context, int64, string, int, int) ([]models.UserSearchResult, error)
+} = (*MockUserRepository)(nil)
+
+// MockInviteRepository mocks friendship invite storage.
+type MockInviteRepository struct {
+	mock.Mock
+}
+
+func (m *MockInviteRepository) Create(ctx context.Context, inviterID int64, expiresAt time.Time, singleUse bool) (*models.Invite, error) {
+	args := m.Called(ctx, inviterID, expiresAt, singleUse)
+	var invite *models.Invite
+	if val := args.Get(0); val != nil {
+		invite = val.(*models.Invite)
+	}
+	return invite, args.Error(1)
+}
+
+func (m *MockInviteRepository) ListByInviter(ctx context.Context, inviterID int64) ([]models.Invite, error) {
+	args := m.Called(ctx, inviterID)
+	var invites []models.Invite
+	if val := args.Get(0); val != nil {
+		invites = val.([]models.Invite)
+	}
+	return invites, args.Error(1)
+}
+
+func (m *MockInviteRepository) Revoke(ctx context.Context, id string, inviterID int64) error {
+	args := m.Called(ctx, id, inviterID)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepository) Redeem(ctx context.Context, id string, redeemerID int64) (*models.Invite, error) {
+	args := m.Called(ctx, id, redeemerID)
+	var invite *models.Invite
+	if val := args.Get(0); val != nil {
+		invite = val.(*models.Invite)
+	}
+	return invite, args.Error(1)
+}
+
+var _ interface {
+	Create(context.Context, int64, time.Time, bool) (*models.Invite, error)
+	ListByInviter(context.Context, int64) ([]models.Invite, error)
+	Revoke(context.Context, string, int64) error
+	Redeem(context.Context, string, int64) (*models.Invite, error)
+} = (*MockInviteRepository)(nil)
+
 // MockPublisher mocks RabbitMQ publisher behavior for telemetry.
 type MockPublisher struct {
 	mock.Mock
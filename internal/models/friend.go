@@ -10,8 +10,25 @@ type FriendRequest struct {
 	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
+// DeletedUserData reports what DeleteUserData removed for one user.
+type DeletedUserData struct {
+	FriendIDs []int64
+	Requests  int64
+}
+
 type Friendship struct {
 	ID       int64 `db:"id" json:"id"`
 	UserID   int64 `db:"user_id" json:"user_id"`
 	FriendID int64 `db:"friend_id" json:"friend_id"`
 }
+
+// Invite is a shareable link that befriends its redeemer with InviterID.
+type Invite struct {
+	ID        string     `db:"id" json:"id"`
+	InviterID int64      `db:"inviter_id" json:"inviter_id"`
+	SingleUse bool       `db:"single_use" json:"single_use"`
+	Uses      int64      `db:"uses" json:"uses"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
@@ -1,6 +1,18 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       int64  `db:"id" json:"id"`
-	Username string `db:"username" json:"username"`
+	ID        int64      `db:"id" json:"id"`
+	Username  string     `db:"username" json:"username"`
+	CreatedAt *time.Time `db:"created_at" json:"created_at,omitempty"`
+}
+
+// UserSearchResult is a user matching a username search, seen from the
+// searcher.
+type UserSearchResult struct {
+	ID            int64  `db:"id" json:"id"`
+	Username      string `db:"username" json:"username"`
+	IsFriend      bool   `db:"is_friend" json:"is_friend"`
+	MutualFriends int64  `db:"mutual_friends" json:"mutual_friends"`
 }
@@ -0,0 +1,27 @@
+// Package openapi serves the OpenAPI 3 description of the HTTP API.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Spec is the OpenAPI document. Keep it in step with internal/router; the
+// router tests fail when a route is missing from it.
+//
+//go:embed openapi.json
+var Spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+func ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", Spec)
+}
+
+// ServeDocs renders Spec with Swagger UI, loaded from a CDN.
+func ServeDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
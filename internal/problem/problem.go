@@ -0,0 +1,84 @@
+// Package problem defines the RFC 7807 problem details body every API error
+// is returned as, and the stable codes it carries. Handlers and middleware
+// both write it, so it depends on neither.
+package problem
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Error codes are part of the API contract: clients match on them, so an
+// existing code must never be renamed or reused for another condition.
+const (
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeTokenRevoked         = "TOKEN_REVOKED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInUse  = "IDEMPOTENCY_KEY_IN_USE"
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeAmbiguousUsername    = "AMBIGUOUS_USERNAME"
+	CodeRequestNotFound      = "FRIEND_REQUEST_NOT_FOUND"
+	CodeFriendshipNotFound   = "FRIENDSHIP_NOT_FOUND"
+	CodeSelfFriendRequest    = "SELF_FRIEND_REQUEST"
+	CodeFriendRequestExists  = "FRIEND_REQUEST_EXISTS"
+	CodeAlreadyFriends       = "ALREADY_FRIENDS"
+	CodeDailyLimitReached    = "DAILY_LIMIT_REACHED"
+	CodeTooManyStreams       = "TOO_MANY_STREAMS"
+	CodeExportNotFound       = "EXPORT_NOT_FOUND"
+	CodeExportNotReady       = "EXPORT_NOT_READY"
+	CodeExportFailed         = "EXPORT_FAILED"
+	CodeInviteNotFound       = "INVITE_NOT_FOUND"
+	CodeInviteExpired        = "INVITE_EXPIRED"
+	CodeInviteRevoked        = "INVITE_REVOKED"
+	CodeInviteUsed           = "INVITE_USED"
+	CodeUpstreamFailure      = "UPSTREAM_FAILURE"
+	CodeUnavailable          = "SERVICE_UNAVAILABLE"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// FieldError describes one invalid field of a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details body. Code is the stable,
+// machine-readable reason; Detail is for humans and may change.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Code      string       `json:"code"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	return p.Code + ": " + p.Detail
+}
+
+// Write aborts the request with p as its response. Callers fill in RequestID;
+// Instance is the request path.
+func Write(c *gin.Context, p *Problem) {
+	p.Instance = c.Request.URL.Path
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
@@ -0,0 +1,153 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"user-service/internal/logging"
+)
+
+const (
+	consumerPrefetch   = 10
+	consumerRetryDelay = 5 * time.Second
+)
+
+// Handler processes one message body. Returning an error requeues the
+// message once; a message that fails again is moved to the dead-letter
+// queue, from where it can be shovelled back once the cause is fixed.
+type Handler func(ctx context.Context, routingKey string, body []byte) error
+
+// Consumer binds a durable queue to a topic exchange and feeds its messages
+// to a Handler. Rejected messages go to the "<queue>.dead-letter" queue
+// through a fanout exchange of the same name. A queue declared before the
+// dead-letter exchange was added must be deleted once so it can be
+// redeclared with it.
+type Consumer struct {
+	amqpURL      string
+	exchangeName string
+	queueName    string
+	routingKeys  []string
+	handler      Handler
+}
+
+func NewConsumer(amqpURL, exchangeName, queueName string, routingKeys []string, handler Handler) *Consumer {
+	return &Consumer{
+		amqpURL:      amqpURL,
+		exchangeName: exchangeName,
+		queueName:    queueName,
+		routingKeys:  routingKeys,
+		handler:      handler,
+	}
+}
+
+// Run consumes until ctx is cancelled, reconnecting whenever the connection
+// to RabbitMQ is lost.
+func (c *Consumer) Run(ctx context.Context) {
+	for {
+		err := c.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logging.FromContext(ctx).Warn("RabbitMQ consumer stopped; reconnecting",
+			"queue", c.queueName,
+			"retry_in", consumerRetryDelay.String(),
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(consumerRetryDelay):
+		}
+	}
+}
+
+func (c *Consumer) consume(ctx context.Context) error {
+	conn, err := amqp.Dial(c.amqpURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(c.exchangeName, "topic", true, false, false, false, nil); err != nil {
+		return err
+	}
+	deadLetter := c.queueName + ".dead-letter"
+	if err := ch.ExchangeDeclare(deadLetter, "fanout", true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(deadLetter, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(deadLetter, "", deadLetter, false, nil); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(c.queueName, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange": deadLetter,
+	}); err != nil {
+		return err
+	}
+	for _, key := range c.routingKeys {
+		if err := ch.QueueBind(c.queueName, key, c.exchangeName, false, nil); err != nil {
+			return err
+		}
+	}
+	if err := ch.Qos(consumerPrefetch, 0, false); err != nil {
+		return err
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, c.queueName, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	for delivery := range deliveries {
+		c.process(ctx, delivery)
+	}
+	return errors.New("delivery channel closed")
+}
+
+func (c *Consumer) process(ctx context.Context, delivery amqp.Delivery) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(delivery.Headers))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, c.exchangeName+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitMQ,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingDestinationName(c.exchangeName),
+			semconv.MessagingRabbitMQDestinationRoutingKey(delivery.RoutingKey),
+		),
+	)
+	defer span.End()
+
+	err := c.handler(ctx, delivery.RoutingKey, delivery.Body)
+	if err == nil {
+		if ackErr := delivery.Ack(false); ackErr != nil {
+			logging.FromContext(ctx).Warn("failed to ack message", "routing_key", delivery.RoutingKey, "error", ackErr)
+		}
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	requeue := !delivery.Redelivered
+	logging.FromContext(ctx).Error("failed to handle message",
+		"routing_key", delivery.RoutingKey,
+		"requeue", requeue,
+		"error", err,
+	)
+	if nackErr := delivery.Nack(false, requeue); nackErr != nil {
+		logging.FromContext(ctx).Warn("failed to nack message", "routing_key", delivery.RoutingKey, "error", nackErr)
+	}
+}
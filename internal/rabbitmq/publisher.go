@@ -3,13 +3,20 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"sync"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"user-service/internal/logging"
 )
 
+const tracerName = "user-service/internal/rabbitmq"
+
 type Publisher interface {
 	Publish(ctx context.Context, routingKey string, event any) error
 	Close() error
@@ -58,7 +65,7 @@ type noopPublisher struct{}
 func NewNoopPublisher() Publisher { return &noopPublisher{} }
 
 func (n *noopPublisher) Publish(ctx context.Context, routingKey string, event any) error {
-	log.Printf("warning: RabbitMQ not configured; skipping publish for routing key %s", routingKey)
+	logging.FromContext(ctx).Debug("RabbitMQ not configured; skipping publish", "routing_key", routingKey)
 	return nil
 }
 
@@ -80,21 +87,42 @@ func (p *publisher) Publish(ctx context.Context, routingKey string, event any) e
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, p.exchangeName+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemRabbitMQ,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(p.exchangeName),
+			semconv.MessagingRabbitMQDestinationRoutingKey(routingKey),
+		),
+	)
+	defer span.End()
+
+	// Consumers continue the trace from the W3C headers on the message.
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return p.channel.PublishWithContext(ctx,
+	err = p.channel.PublishWithContext(ctx,
 		p.exchangeName,
 		routingKey,
 		false, // mandatory
 		false, // immediate
 		amqp.Publishing{
+			Headers:      headers,
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
 			DeliveryMode: amqp.Persistent,
 		},
 	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
 }
 
 func (p *publisher) Close() error {
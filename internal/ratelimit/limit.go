@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes a token bucket: it holds at most Burst tokens and refills
+// at Rate tokens per second. Each request takes one token.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses "<count>/<period>", e.g. "10/1m", into a bucket that
+// allows count requests at once and refills count tokens every period.
+func ParseLimit(spec string) (Limit, error) {
+	countPart, periodPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <count>/<period>", spec)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countPart))
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: count must be a positive integer", spec)
+	}
+	period, err := time.ParseDuration(strings.TrimSpace(periodPart))
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", spec)
+	}
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: count}, nil
+}
+
+// Store keeps token buckets by key. Take consumes a token from key's bucket
+// and reports whether one was available; when it was not, retryAfter is how
+// long until the next token is added.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// refill returns the token count of a bucket that held tokens elapsed ago.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// take spends one token when available and returns the new token count.
+func take(tokens float64, limit Limit) (float64, bool, time.Duration) {
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	return tokens, false, wait
+}
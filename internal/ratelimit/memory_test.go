@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("10/1m")
+	require.NoError(t, err)
+	require.Equal(t, 10, limit.Burst)
+	require.InDelta(t, 10.0/60, limit.Rate, 1e-9)
+
+	for _, spec := range []string{"10", "0/1m", "-1/1m", "10/soon", "10/0s"} {
+		_, err := ParseLimit(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestMemoryStoreRefills(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := store.Take(ctx, "k", limit)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := store.Take(ctx, "k", limit)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, time.Second, retryAfter)
+
+	// Other keys have their own bucket.
+	allowed, _, _ = store.Take(ctx, "other", limit)
+	require.True(t, allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, retryAfter, _ = store.Take(ctx, "k", limit)
+	require.False(t, allowed)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _, _ = store.Take(ctx, "k", limit)
+	require.True(t, allowed)
+}
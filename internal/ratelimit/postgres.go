@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that every
+// replica shares the same limits. Elapsed time is measured with the database
+// clock to avoid skew between replicas.
+type PostgresStore struct {
+	db *sqlx.DB
+}
+
+func NewPostgresStore(db *sqlx.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to begin rate limit transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO NOTHING
+	`, key, float64(limit.Burst)); err != nil {
+		return false, 0, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var row struct {
+		Tokens  float64 `db:"tokens"`
+		Elapsed float64 `db:"elapsed"`
+	}
+	err = tx.GetContext(ctx, &row, `
+		SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at)::float8 AS elapsed
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Swept between the insert and the select; treat as a full bucket.
+		row.Tokens, err = float64(limit.Burst), nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to load rate limit bucket: %w", err)
+	}
+
+	tokens := refill(row.Tokens, time.Duration(row.Elapsed*float64(time.Second)), limit)
+	tokens, allowed, retryAfter = take(tokens, limit)
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens = $2, updated_at = NOW() WHERE key = $1
+	`, key, tokens); err != nil {
+		return false, 0, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return false, 0, fmt.Errorf("failed to commit rate limit bucket: %w", err)
+	}
+	return allowed, retryAfter, nil
+}
+
+// Sweep deletes buckets untouched for longer than idle. Any bucket idle for
+// its full refill period is indistinguishable from a missing one.
+func (s *PostgresStore) Sweep(ctx context.Context, idle time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => $1)
+	`, idle.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("failed to sweep rate limit buckets: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"user-service/internal/metrics"
+)
+
+var (
+	ErrTooManyStreams = errors.New("too many open streams for user")
+	ErrRegistryClosed = errors.New("stream registry is closed")
+)
+
+// Registry tracks the client event streams open on this instance so they can
+// be limited per user and closed together on shutdown.
+type Registry struct {
+	mu         sync.Mutex
+	conns      map[int64]map[*conn]struct{}
+	maxPerUser int
+	closed     bool
+}
+
+type conn struct {
+	cancel context.CancelFunc
+}
+
+// NewRegistry creates a registry; maxPerUser <= 0 disables the per-user limit.
+func NewRegistry(maxPerUser int) *Registry {
+	return &Registry{conns: make(map[int64]map[*conn]struct{}), maxPerUser: maxPerUser}
+}
+
+// Register records a stream for userID. The returned context is cancelled
+// when CloseAll is called; release must be called when the stream ends.
+func (r *Registry) Register(ctx context.Context, userID int64, transport string) (context.Context, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, nil, ErrRegistryClosed
+	}
+	if r.maxPerUser > 0 && len(r.conns[userID]) >= r.maxPerUser {
+		return nil, nil, ErrTooManyStreams
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	c := &conn{cancel: cancel}
+	if r.conns[userID] == nil {
+		r.conns[userID] = make(map[*conn]struct{})
+	}
+	r.conns[userID][c] = struct{}{}
+	metrics.StreamOpened(transport)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			cancel()
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			delete(r.conns[userID], c)
+			if len(r.conns[userID]) == 0 {
+				delete(r.conns, userID)
+			}
+			metrics.StreamClosed(transport)
+		})
+	}
+	return streamCtx, release, nil
+}
+
+// Active returns the number of open streams.
+func (r *Registry) Active() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, set := range r.conns {
+		total += len(set)
+	}
+	return total
+}
+
+// CloseAll cancels every open stream and rejects new ones. Long-lived streams
+// would otherwise hold http.Server.Shutdown until its deadline.
+func (r *Registry) CloseAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for _, set := range r.conns {
+		for c := range set {
+			c.cancel()
+		}
+	}
+}
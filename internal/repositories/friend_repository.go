@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
 	"time"
 	"user-service/internal/rabbitmq"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
+	"user-service/internal/events"
+	"user-service/internal/logging"
 	"user-service/internal/models"
 )
 
@@ -18,20 +20,44 @@ var ErrRequestForbidden = errors.New("friend request not allowed")
 type FriendRepository interface {
 	CreateRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error)
 	GetIncomingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error)
+	GetOutgoingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error)
 	AcceptRequest(ctx context.Context, requestID, userID int64) error
 	RejectRequest(ctx context.Context, requestID, userID int64) error
 	ListFriends(ctx context.Context, userID int64) ([]int64, error)
 	HasPendingRequest(ctx context.Context, fromUserID, toUserID int64) (bool, error)
 	AreFriends(ctx context.Context, userID, otherID int64) (bool, error)
+	AreFriendsOnPrimary(ctx context.Context, userID, otherID int64) (bool, error)
+	CountFriends(ctx context.Context, userID int64) (int64, error)
+	FilterFriends(ctx context.Context, userID int64, candidateIDs []int64) ([]int64, error)
+	RemoveFriendship(ctx context.Context, userID, friendID int64) (bool, error)
+	PurgePendingRequests(ctx context.Context, fromUserID int64) (int64, error)
+	CountRequestsSince(ctx context.Context, fromUserID int64, since time.Time) (int64, error)
+	RequestOutcomesSince(ctx context.Context, fromUserID int64, since time.Time) (accepted, rejected int64, err error)
+	CreateHeldRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error)
+	ListHeldRequests(ctx context.Context, limit int) ([]models.FriendRequest, error)
+	ReviewHeldRequest(ctx context.Context, requestID int64, release bool) (*models.FriendRequest, error)
+	DeleteUserData(ctx context.Context, userID int64) (models.DeletedUserData, error)
+	ListAllRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error)
+	CountAllRequests(ctx context.Context, userID int64) (int64, error)
 }
 
 type friendRepository struct {
 	db        *sqlx.DB
+	replica   *sqlx.DB
 	publisher rabbitmq.Publisher
+	bus       *events.Bus
 }
 
-func NewFriendRepository(db *sqlx.DB, publisher rabbitmq.Publisher) FriendRepository {
-	return &friendRepository{db: db, publisher: publisher}
+// NewFriendRepository creates a repository that announces committed changes on
+// the RabbitMQ publisher and, when bus is non-nil, the in-process event bus.
+// Reads that tolerate replication lag go to replica when it is non-nil;
+// everything else, including reads that must see the caller's own writes,
+// uses db.
+func NewFriendRepository(db, replica *sqlx.DB, publisher rabbitmq.Publisher, bus *events.Bus) FriendRepository {
+	if replica == nil {
+		replica = db
+	}
+	return &friendRepository{db: db, replica: replica, publisher: publisher, bus: bus}
 }
 
 func (r *friendRepository) CreateRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error) {
@@ -45,19 +71,20 @@ RETURNING id, from_user_id, to_user_id, status, created_at
 		return nil, err
 	}
 
-	r.logPublish(ctx, "friend.request.created", map[string]any{
+	logPublish(ctx, r.publisher, "friend.request.created", map[string]any{
 		"request_id":   req.ID,
 		"from_user_id": req.FromUserID,
 		"to_user_id":   req.ToUserID,
 		"created_at":   req.CreatedAt,
 	})
+	r.bus.Publish(pairEvents(events.RequestCreated, req.FromUserID, req.ToUserID, req.ID, req.CreatedAt)...)
 
 	return &req, nil
 }
 
 func (r *friendRepository) GetIncomingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
 	var reqs []models.FriendRequest
-	err := r.db.SelectContext(ctx, &reqs, `
+	err := r.replica.SelectContext(ctx, &reqs, `
 SELECT id, from_user_id, to_user_id, status, created_at
 FROM friend_requests
 WHERE to_user_id=$1 AND status='pending'
@@ -66,9 +93,25 @@ ORDER BY created_at DESC
 	return reqs, err
 }
 
+// GetOutgoingRequests lists the open requests userID sent. Held requests are
+// reported as pending so senders cannot tell they are under review. Unlike
+// incoming requests these are the caller's own writes, which a sender expects
+// to see right after sending, so it reads the primary.
+func (r *friendRepository) GetOutgoingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	var reqs []models.FriendRequest
+	err := r.db.SelectContext(ctx, &reqs, `
+SELECT id, from_user_id, to_user_id, 'pending' AS status, created_at
+FROM friend_requests
+WHERE from_user_id=$1 AND status IN ('pending','held')
+ORDER BY created_at DESC
+`, userID)
+	return reqs, err
+}
+
 func (r *friendRepository) AcceptRequest(ctx context.Context, requestID, userID int64) error {
 	var eventPayload map[string]any
-	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+	var busEvents []events.Event
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
 		var req models.FriendRequest
 		if err := tx.GetContext(ctx, &req, `SELECT id, from_user_id, to_user_id, status, created_at FROM friend_requests WHERE id=$1`, requestID); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
@@ -76,6 +119,9 @@ func (r *friendRepository) AcceptRequest(ctx context.Context, requestID, userID
 			}
 			return err
 		}
+		if req.Status == "held" {
+			return sql.ErrNoRows
+		}
 		if req.ToUserID != userID {
 			return ErrRequestForbidden
 		}
@@ -89,10 +135,10 @@ func (r *friendRepository) AcceptRequest(ctx context.Context, requestID, userID
 			return err
 		}
 
-		if err := r.insertFriendship(ctx, tx, req.FromUserID, req.ToUserID); err != nil {
+		if err := insertFriendship(ctx, tx, req.FromUserID, req.ToUserID); err != nil {
 			return err
 		}
-		if err := r.insertFriendship(ctx, tx, req.ToUserID, req.FromUserID); err != nil {
+		if err := insertFriendship(ctx, tx, req.ToUserID, req.FromUserID); err != nil {
 			return err
 		}
 
@@ -100,7 +146,12 @@ func (r *friendRepository) AcceptRequest(ctx context.Context, requestID, userID
 			"user_id":     req.FromUserID,
 			"friend_id":   req.ToUserID,
 			"accepted_at": acceptedAt,
+			"source":      "request",
 		}
+		busEvents = append(
+			pairEvents(events.RequestAccepted, req.FromUserID, req.ToUserID, req.ID, acceptedAt),
+			pairEvents(events.FriendshipCreated, req.FromUserID, req.ToUserID, req.ID, acceptedAt)...,
+		)
 		return nil
 	})
 	if err != nil {
@@ -108,21 +159,25 @@ func (r *friendRepository) AcceptRequest(ctx context.Context, requestID, userID
 	}
 
 	if eventPayload != nil {
-		r.logPublish(ctx, "friendship.created", eventPayload)
+		logPublish(ctx, r.publisher, "friendship.created", eventPayload)
 	}
+	r.bus.Publish(busEvents...)
 
 	return nil
 }
 
 func (r *friendRepository) RejectRequest(ctx context.Context, requestID, userID int64) error {
-	var toUserID int64
-	if err := r.db.GetContext(ctx, &toUserID, `SELECT to_user_id FROM friend_requests WHERE id=$1`, requestID); err != nil {
+	var req models.FriendRequest
+	if err := r.db.GetContext(ctx, &req, `SELECT id, from_user_id, to_user_id, status, created_at FROM friend_requests WHERE id=$1`, requestID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return sql.ErrNoRows
 		}
 		return err
 	}
-	if toUserID != userID {
+	if req.Status == "held" {
+		return sql.ErrNoRows
+	}
+	if req.ToUserID != userID {
 		return ErrRequestForbidden
 	}
 	res, err := r.db.ExecContext(ctx, `
@@ -139,12 +194,13 @@ WHERE id=$1 AND to_user_id=$2 AND status='pending'
 	if count == 0 {
 		return sql.ErrNoRows
 	}
+	r.bus.Publish(pairEvents(events.RequestRejected, req.FromUserID, req.ToUserID, req.ID, time.Now().UTC())...)
 	return nil
 }
 
 func (r *friendRepository) ListFriends(ctx context.Context, userID int64) ([]int64, error) {
 	var friends []int64
-	err := r.db.SelectContext(ctx, &friends, `
+	err := r.replica.SelectContext(ctx, &friends, `
 SELECT friend_id
 FROM friendships
 WHERE user_id=$1
@@ -153,21 +209,37 @@ ORDER BY friend_id
 	return friends, err
 }
 
+// HasPendingRequest reports whether a pending request exists in either
+// direction. A held request counts only for its sender, so recipients cannot
+// learn about it. It guards against duplicate requests, so it reads the
+// primary.
 func (r *friendRepository) HasPendingRequest(ctx context.Context, fromUserID, toUserID int64) (bool, error) {
 	var exists bool
 	err := r.db.GetContext(ctx, &exists, `
 SELECT EXISTS(
 SELECT 1 FROM friend_requests
-WHERE ((from_user_id=$1 AND to_user_id=$2) OR (from_user_id=$2 AND to_user_id=$1))
-AND status='pending'
+WHERE (from_user_id=$1 AND to_user_id=$2 AND status IN ('pending','held'))
+OR (from_user_id=$2 AND to_user_id=$1 AND status='pending')
 )
 `, fromUserID, toUserID)
 	return exists, err
 }
 
+// AreFriends reads the replica. Callers that guard a write on the answer use
+// AreFriendsOnPrimary instead.
 func (r *friendRepository) AreFriends(ctx context.Context, userID, otherID int64) (bool, error) {
+	return areFriends(ctx, r.replica, userID, otherID)
+}
+
+// AreFriendsOnPrimary is AreFriends read from the primary, so SendRequest can
+// refuse requests between users who just became friends.
+func (r *friendRepository) AreFriendsOnPrimary(ctx context.Context, userID, otherID int64) (bool, error) {
+	return areFriends(ctx, r.db, userID, otherID)
+}
+
+func areFriends(ctx context.Context, db *sqlx.DB, userID, otherID int64) (bool, error) {
 	var exists bool
-	err := r.db.GetContext(ctx, &exists, `
+	err := db.GetContext(ctx, &exists, `
 SELECT EXISTS(
 SELECT 1 FROM friendships WHERE user_id=$1 AND friend_id=$2
 )
@@ -175,7 +247,325 @@ SELECT 1 FROM friendships WHERE user_id=$1 AND friend_id=$2
 	return exists, err
 }
 
-func (r *friendRepository) insertFriendship(ctx context.Context, tx *sqlx.Tx, userID, friendID int64) error {
+// CountFriends reads the replica; a count that briefly lags is harmless.
+func (r *friendRepository) CountFriends(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.replica.GetContext(ctx, &count, `SELECT COUNT(*) FROM friendships WHERE user_id=$1`, userID)
+	return count, err
+}
+
+// FilterFriends returns the subset of candidateIDs that are friends of userID.
+// Like AreFriends it reads the replica.
+func (r *friendRepository) FilterFriends(ctx context.Context, userID int64, candidateIDs []int64) ([]int64, error) {
+	if len(candidateIDs) == 0 {
+		return nil, nil
+	}
+	var friends []int64
+	err := r.replica.SelectContext(ctx, &friends, `
+SELECT friend_id
+FROM friendships
+WHERE user_id=$1 AND friend_id = ANY($2)
+ORDER BY friend_id
+`, userID, pq.Array(candidateIDs))
+	return friends, err
+}
+
+// RemoveFriendship deletes both directions of a friendship. It reports
+// whether anything was removed.
+func (r *friendRepository) RemoveFriendship(ctx context.Context, userID, friendID int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+DELETE FROM friendships
+WHERE (user_id=$1 AND friend_id=$2) OR (user_id=$2 AND friend_id=$1)
+`, userID, friendID)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	removedAt := time.Now().UTC()
+	logPublish(ctx, r.publisher, "friendship.removed", map[string]any{
+		"user_id":    userID,
+		"friend_id":  friendID,
+		"removed_at": removedAt,
+	})
+	r.bus.Publish(pairEvents(events.FriendshipRemoved, userID, friendID, 0, removedAt)...)
+	return true, nil
+}
+
+// PurgePendingRequests rejects every pending or held request sent by
+// fromUserID and returns how many were affected. Recipients never saw the
+// held ones, so only the pending ones are announced.
+func (r *friendRepository) PurgePendingRequests(ctx context.Context, fromUserID int64) (int64, error) {
+	var purged []models.FriendRequest
+	err := r.db.SelectContext(ctx, &purged, `
+UPDATE friend_requests f SET status='rejected'
+FROM (
+SELECT id, status FROM friend_requests
+WHERE from_user_id=$1 AND status IN ('pending','held')
+FOR UPDATE
+) old
+WHERE f.id = old.id
+RETURNING f.id, f.from_user_id, f.to_user_id, old.status, f.created_at
+`, fromUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	for _, req := range purged {
+		if req.Status == "pending" {
+			r.bus.Publish(pairEvents(events.RequestRejected, req.FromUserID, req.ToUserID, req.ID, now)...)
+		}
+	}
+	return int64(len(purged)), nil
+}
+
+// CountRequestsSince counts the requests fromUserID sent since the given
+// time, whatever their status.
+func (r *friendRepository) CountRequestsSince(ctx context.Context, fromUserID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, `
+SELECT COUNT(*) FROM friend_requests WHERE from_user_id=$1 AND created_at >= $2
+`, fromUserID, since)
+	return count, err
+}
+
+// RequestOutcomesSince returns how many requests sent by fromUserID since the
+// given time were accepted and rejected.
+func (r *friendRepository) RequestOutcomesSince(ctx context.Context, fromUserID int64, since time.Time) (int64, int64, error) {
+	var outcomes struct {
+		Accepted int64 `db:"accepted"`
+		Rejected int64 `db:"rejected"`
+	}
+	err := r.db.GetContext(ctx, &outcomes, `
+SELECT
+COUNT(*) FILTER (WHERE status='accepted') AS accepted,
+COUNT(*) FILTER (WHERE status='rejected') AS rejected
+FROM friend_requests
+WHERE from_user_id=$1 AND created_at >= $2
+`, fromUserID, since)
+	return outcomes.Accepted, outcomes.Rejected, err
+}
+
+// CreateHeldRequest stores a request awaiting moderator review. Nothing is
+// announced until it is released.
+func (r *friendRepository) CreateHeldRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error) {
+	var req models.FriendRequest
+	err := r.db.QueryRowxContext(ctx, `
+INSERT INTO friend_requests (from_user_id, to_user_id, status)
+VALUES ($1, $2, 'held')
+RETURNING id, from_user_id, to_user_id, status, created_at
+`, fromUserID, toUserID).StructScan(&req)
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *friendRepository) ListHeldRequests(ctx context.Context, limit int) ([]models.FriendRequest, error) {
+	var reqs []models.FriendRequest
+	err := r.db.SelectContext(ctx, &reqs, `
+SELECT id, from_user_id, to_user_id, status, created_at
+FROM friend_requests
+WHERE status='held'
+ORDER BY created_at
+LIMIT $1
+`, limit)
+	return reqs, err
+}
+
+// ReviewHeldRequest releases a held request to its recipient as pending, or
+// rejects it. It returns sql.ErrNoRows when requestID is not held.
+//
+// What happened while the request was held decides how a release ends: if
+// the users became friends meanwhile the request is simply marked accepted,
+// and if the recipient has since sent a pending request the other way both
+// are accepted and the friendship is created, as if the recipient had
+// accepted this one.
+func (r *friendRepository) ReviewHeldRequest(ctx context.Context, requestID int64, release bool) (*models.FriendRequest, error) {
+	var req models.FriendRequest
+	var eventPayload map[string]any
+	var busEvents []events.Event
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &req, `
+SELECT id, from_user_id, to_user_id, status, created_at
+FROM friend_requests WHERE id=$1 AND status='held'
+FOR UPDATE
+`, requestID); err != nil {
+			return err
+		}
+
+		if !release {
+			req.Status = "rejected"
+			_, err := tx.ExecContext(ctx, `UPDATE friend_requests SET status='rejected' WHERE id=$1`, req.ID)
+			return err
+		}
+
+		var friends bool
+		if err := tx.GetContext(ctx, &friends, `
+SELECT EXISTS (SELECT 1 FROM friendships WHERE user_id=$1 AND friend_id=$2)
+`, req.FromUserID, req.ToUserID); err != nil {
+			return err
+		}
+		if friends {
+			req.Status = "accepted"
+			_, err := tx.ExecContext(ctx, `UPDATE friend_requests SET status='accepted' WHERE id=$1`, req.ID)
+			return err
+		}
+
+		var oppositeIDs []int64
+		if err := tx.SelectContext(ctx, &oppositeIDs, `
+UPDATE friend_requests SET status='accepted'
+WHERE from_user_id=$1 AND to_user_id=$2 AND status='pending'
+RETURNING id
+`, req.ToUserID, req.FromUserID); err != nil {
+			return err
+		}
+		if len(oppositeIDs) == 0 {
+			req.Status = "pending"
+			if _, err := tx.ExecContext(ctx, `UPDATE friend_requests SET status='pending' WHERE id=$1`, req.ID); err != nil {
+				return err
+			}
+			eventPayload = map[string]any{
+				"request_id":   req.ID,
+				"from_user_id": req.FromUserID,
+				"to_user_id":   req.ToUserID,
+				"created_at":   req.CreatedAt,
+			}
+			busEvents = pairEvents(events.RequestCreated, req.FromUserID, req.ToUserID, req.ID, time.Now().UTC())
+			return nil
+		}
+
+		req.Status = "accepted"
+		if _, err := tx.ExecContext(ctx, `UPDATE friend_requests SET status='accepted' WHERE id=$1`, req.ID); err != nil {
+			return err
+		}
+		if err := insertFriendship(ctx, tx, req.FromUserID, req.ToUserID); err != nil {
+			return err
+		}
+		if err := insertFriendship(ctx, tx, req.ToUserID, req.FromUserID); err != nil {
+			return err
+		}
+
+		acceptedAt := time.Now().UTC()
+		eventPayload = map[string]any{
+			"user_id":     req.FromUserID,
+			"friend_id":   req.ToUserID,
+			"accepted_at": acceptedAt,
+			"source":      "request",
+		}
+		for _, id := range oppositeIDs {
+			busEvents = append(busEvents, pairEvents(events.RequestAccepted, req.ToUserID, req.FromUserID, id, acceptedAt)...)
+		}
+		busEvents = append(busEvents, pairEvents(events.FriendshipCreated, req.FromUserID, req.ToUserID, req.ID, acceptedAt)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case eventPayload == nil:
+	case req.Status == "pending":
+		logPublish(ctx, r.publisher, "friend.request.created", eventPayload)
+	default:
+		logPublish(ctx, r.publisher, "friendship.created", eventPayload)
+	}
+	r.bus.Publish(busEvents...)
+	return &req, nil
+}
+
+// DeleteUserData removes every friendship and friend request involving
+// userID, revokes their invites and drops their data exports in one
+// transaction, and announces each removed friendship. Running it again for
+// the same user finds nothing and announces nothing.
+func (r *friendRepository) DeleteUserData(ctx context.Context, userID int64) (models.DeletedUserData, error) {
+	var deleted models.DeletedUserData
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		var friendIDs []int64
+		if err := tx.SelectContext(ctx, &friendIDs, `
+DELETE FROM friendships
+WHERE user_id=$1 OR friend_id=$1
+RETURNING CASE WHEN user_id=$1 THEN friend_id ELSE user_id END
+`, userID); err != nil {
+			return err
+		}
+		seen := make(map[int64]bool, len(friendIDs))
+		for _, id := range friendIDs {
+			if !seen[id] {
+				seen[id] = true
+				deleted.FriendIDs = append(deleted.FriendIDs, id)
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, `
+DELETE FROM friend_requests WHERE from_user_id=$1 OR to_user_id=$1
+`, userID)
+		if err != nil {
+			return err
+		}
+		if deleted.Requests, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+UPDATE friend_invites SET revoked_at=NOW()
+WHERE inviter_id=$1 AND revoked_at IS NULL
+`, userID); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM export_jobs WHERE user_id=$1`, userID)
+		return err
+	})
+	if err != nil {
+		return models.DeletedUserData{}, err
+	}
+
+	removedAt := time.Now().UTC()
+	for _, friendID := range deleted.FriendIDs {
+		logPublish(ctx, r.publisher, "friendship.removed", map[string]any{
+			"user_id":    userID,
+			"friend_id":  friendID,
+			"removed_at": removedAt,
+			"reason":     "user_deleted",
+		})
+		r.bus.Publish(pairEvents(events.FriendshipRemoved, userID, friendID, 0, removedAt)...)
+	}
+	return deleted, nil
+}
+
+// ListAllRequests returns every request userID sent and every request they
+// received, whatever the status, oldest first. Held requests are only
+// included for their sender.
+func (r *friendRepository) ListAllRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	var reqs []models.FriendRequest
+	err := r.db.SelectContext(ctx, &reqs, `
+SELECT id, from_user_id, to_user_id, status, created_at
+FROM friend_requests
+WHERE from_user_id=$1 OR (to_user_id=$1 AND status<>'held')
+ORDER BY created_at, id
+`, userID)
+	return reqs, err
+}
+
+// CountAllRequests counts the requests ListAllRequests would return.
+func (r *friendRepository) CountAllRequests(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, `
+SELECT COUNT(*) FROM friend_requests
+WHERE from_user_id=$1 OR (to_user_id=$1 AND status<>'held')
+`, userID)
+	return count, err
+}
+
+func insertFriendship(ctx context.Context, tx *sqlx.Tx, userID, friendID int64) error {
 	_, err := tx.ExecContext(ctx, `
 INSERT INTO friendships (user_id, friend_id) VALUES ($1, $2)
 ON CONFLICT (user_id, friend_id) DO NOTHING
@@ -183,8 +573,8 @@ ON CONFLICT (user_id, friend_id) DO NOTHING
 	return err
 }
 
-func (r *friendRepository) withTx(ctx context.Context, fn func(*sqlx.Tx) error) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
+func withTx(ctx context.Context, db *sqlx.DB, fn func(*sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -195,11 +585,22 @@ func (r *friendRepository) withTx(ctx context.Context, fn func(*sqlx.Tx) error)
 	return tx.Commit()
 }
 
-func (r *friendRepository) logPublish(ctx context.Context, eventType string, payload any) {
-	if r.publisher == nil {
+func logPublish(ctx context.Context, publisher rabbitmq.Publisher, eventType string, payload any) {
+	if publisher == nil {
 		return
 	}
-	if err := r.publisher.Publish(ctx, eventType, payload); err != nil {
-		log.Printf("warning: failed to publish %s: %v", eventType, err)
+	var err error
+	untimed(ctx, func() { err = publisher.Publish(ctx, eventType, payload) })
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to publish event", "event_type", eventType, "error", err)
+	}
+}
+
+// pairEvents builds the bus events for a change between two users, one
+// addressed to each side.
+func pairEvents(eventType events.Type, userID, otherID, requestID int64, at time.Time) []events.Event {
+	return []events.Event{
+		{Type: eventType, UserID: userID, OtherUserID: otherID, RequestID: requestID, OccurredAt: at},
+		{Type: eventType, UserID: otherID, OtherUserID: userID, RequestID: requestID, OccurredAt: at},
 	}
 }
@@ -0,0 +1,234 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"user-service/internal/logging"
+	"user-service/internal/metrics"
+	"user-service/internal/models"
+)
+
+// instrumentedFriendRepository records the duration and outcome of every
+// FriendRepository call and logs calls slower than slowThreshold. Time spent
+// publishing events to RabbitMQ is left out, so a slow broker does not show
+// up as slow queries.
+type instrumentedFriendRepository struct {
+	next          FriendRepository
+	slowThreshold time.Duration
+}
+
+// NewInstrumentedFriendRepository wraps next with query metrics. A zero
+// slowThreshold disables the slow query log.
+func NewInstrumentedFriendRepository(next FriendRepository, slowThreshold time.Duration) FriendRepository {
+	return &instrumentedFriendRepository{next: next, slowThreshold: slowThreshold}
+}
+
+// queryTimer times one repository call, less the steps run through untimed.
+type queryTimer struct {
+	start    time.Time
+	excluded time.Duration
+}
+
+type queryTimerKey struct{}
+
+func startQueryTimer(ctx context.Context) (context.Context, *queryTimer) {
+	timer := &queryTimer{start: time.Now()}
+	return context.WithValue(ctx, queryTimerKey{}, timer), timer
+}
+
+// untimed runs fn, a step of a repository call that is not database work,
+// without counting it towards the call's query duration.
+func untimed(ctx context.Context, fn func()) {
+	timer, ok := ctx.Value(queryTimerKey{}).(*queryTimer)
+	if !ok {
+		fn()
+		return
+	}
+	start := time.Now()
+	fn()
+	timer.excluded += time.Since(start)
+}
+
+func (r *instrumentedFriendRepository) observe(ctx context.Context, operation string, timer *queryTimer, err error) {
+	observeQuery(ctx, operation, timer, err, r.slowThreshold)
+}
+
+// observeQuery records a repository call and logs it when it took at least
+// slowThreshold.
+func observeQuery(ctx context.Context, operation string, timer *queryTimer, err error, slowThreshold time.Duration) {
+	elapsed := time.Since(timer.start) - timer.excluded
+	metrics.ObserveDBQuery(operation, queryOutcome(err), elapsed.Seconds())
+	if slowThreshold > 0 && elapsed >= slowThreshold {
+		logging.FromContext(ctx).Warn("slow query",
+			"operation", operation,
+			"duration_ms", elapsed.Milliseconds(),
+			"threshold_ms", slowThreshold.Milliseconds(),
+		)
+	}
+}
+
+func queryOutcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.Is(err, sql.ErrNoRows):
+		return metrics.OutcomeNotFound
+	case errors.Is(err, ErrRequestForbidden):
+		return metrics.OutcomeForbidden
+	case errors.Is(err, ErrInviteExpired), errors.Is(err, ErrInviteRevoked), errors.Is(err, ErrInviteUsed),
+		errors.Is(err, ErrOwnInvite), errors.Is(err, ErrAlreadyFriends):
+		return metrics.OutcomeRejected
+	default:
+		return metrics.OutcomeError
+	}
+}
+
+func (r *instrumentedFriendRepository) CreateRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.CreateRequest(ctx, fromUserID, toUserID)
+	r.observe(ctx, "create_request", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) GetIncomingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.GetIncomingRequests(ctx, userID)
+	r.observe(ctx, "get_incoming_requests", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) GetOutgoingRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.GetOutgoingRequests(ctx, userID)
+	r.observe(ctx, "get_outgoing_requests", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) AcceptRequest(ctx context.Context, requestID, userID int64) error {
+	ctx, timer := startQueryTimer(ctx)
+	err := r.next.AcceptRequest(ctx, requestID, userID)
+	r.observe(ctx, "accept_request", timer, err)
+	return err
+}
+
+func (r *instrumentedFriendRepository) RejectRequest(ctx context.Context, requestID, userID int64) error {
+	ctx, timer := startQueryTimer(ctx)
+	err := r.next.RejectRequest(ctx, requestID, userID)
+	r.observe(ctx, "reject_request", timer, err)
+	return err
+}
+
+func (r *instrumentedFriendRepository) ListFriends(ctx context.Context, userID int64) ([]int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.ListFriends(ctx, userID)
+	r.observe(ctx, "list_friends", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) HasPendingRequest(ctx context.Context, fromUserID, toUserID int64) (bool, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.HasPendingRequest(ctx, fromUserID, toUserID)
+	r.observe(ctx, "has_pending_request", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) AreFriends(ctx context.Context, userID, otherID int64) (bool, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.AreFriends(ctx, userID, otherID)
+	r.observe(ctx, "are_friends", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) AreFriendsOnPrimary(ctx context.Context, userID, otherID int64) (bool, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.AreFriendsOnPrimary(ctx, userID, otherID)
+	r.observe(ctx, "are_friends_on_primary", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) CountFriends(ctx context.Context, userID int64) (int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.CountFriends(ctx, userID)
+	r.observe(ctx, "count_friends", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) FilterFriends(ctx context.Context, userID int64, candidateIDs []int64) ([]int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.FilterFriends(ctx, userID, candidateIDs)
+	r.observe(ctx, "filter_friends", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) RemoveFriendship(ctx context.Context, userID, friendID int64) (bool, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.RemoveFriendship(ctx, userID, friendID)
+	r.observe(ctx, "remove_friendship", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) PurgePendingRequests(ctx context.Context, fromUserID int64) (int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.PurgePendingRequests(ctx, fromUserID)
+	r.observe(ctx, "purge_pending_requests", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) CountRequestsSince(ctx context.Context, fromUserID int64, since time.Time) (int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.CountRequestsSince(ctx, fromUserID, since)
+	r.observe(ctx, "count_requests_since", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) RequestOutcomesSince(ctx context.Context, fromUserID int64, since time.Time) (int64, int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	accepted, rejected, err := r.next.RequestOutcomesSince(ctx, fromUserID, since)
+	r.observe(ctx, "request_outcomes_since", timer, err)
+	return accepted, rejected, err
+}
+
+func (r *instrumentedFriendRepository) CreateHeldRequest(ctx context.Context, fromUserID, toUserID int64) (*models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.CreateHeldRequest(ctx, fromUserID, toUserID)
+	r.observe(ctx, "create_held_request", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) ListHeldRequests(ctx context.Context, limit int) ([]models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.ListHeldRequests(ctx, limit)
+	r.observe(ctx, "list_held_requests", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) ReviewHeldRequest(ctx context.Context, requestID int64, release bool) (*models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.ReviewHeldRequest(ctx, requestID, release)
+	r.observe(ctx, "review_held_request", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) DeleteUserData(ctx context.Context, userID int64) (models.DeletedUserData, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.DeleteUserData(ctx, userID)
+	r.observe(ctx, "delete_user_data", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) ListAllRequests(ctx context.Context, userID int64) ([]models.FriendRequest, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.ListAllRequests(ctx, userID)
+	r.observe(ctx, "list_all_requests", timer, err)
+	return result, err
+}
+
+func (r *instrumentedFriendRepository) CountAllRequests(ctx context.Context, userID int64) (int64, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.CountAllRequests(ctx, userID)
+	r.observe(ctx, "count_all_requests", timer, err)
+	return result, err
+}
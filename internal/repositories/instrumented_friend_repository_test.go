@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/logging"
+	"user-service/internal/models"
+)
+
+// stubFriendRepository implements only the methods the tests call.
+type stubFriendRepository struct {
+	FriendRepository
+	delay time.Duration
+	err   error
+}
+
+func (s stubFriendRepository) AreFriends(context.Context, int64, int64) (bool, error) {
+	time.Sleep(s.delay)
+	return s.err == nil, s.err
+}
+
+func (s stubFriendRepository) AcceptRequest(ctx context.Context, _, _ int64) error {
+	untimed(ctx, func() { time.Sleep(s.delay) })
+	return s.err
+}
+
+func queryCount(t *testing.T, operation, outcome string) uint64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "db_query_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["outcome"] == outcome {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestInstrumentedRepositoryRecordsOutcome(t *testing.T) {
+	repo := NewInstrumentedFriendRepository(stubFriendRepository{}, 0)
+	before := queryCount(t, "are_friends", "success")
+	_, err := repo.AreFriends(context.Background(), 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, before+1, queryCount(t, "are_friends", "success"))
+
+	for err, outcome := range map[error]string{
+		sql.ErrNoRows:       "not_found",
+		ErrRequestForbidden: "forbidden",
+		sql.ErrConnDone:     "error",
+	} {
+		repo := NewInstrumentedFriendRepository(stubFriendRepository{err: err}, 0)
+		before := queryCount(t, "accept_request", outcome)
+		require.ErrorIs(t, repo.AcceptRequest(context.Background(), 1, 2), err)
+		require.Equal(t, before+1, queryCount(t, "accept_request", outcome))
+	}
+}
+
+func TestInstrumentedRepositoryLogsSlowQueries(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "info")
+	require.NoError(t, err)
+	ctx := logging.WithLogger(context.Background(), logger)
+
+	fast := NewInstrumentedFriendRepository(stubFriendRepository{}, time.Hour)
+	_, err = fast.AreFriends(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	slow := NewInstrumentedFriendRepository(stubFriendRepository{delay: 5 * time.Millisecond}, time.Millisecond)
+	_, err = slow.AreFriends(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `"msg":"slow query"`)
+	require.Contains(t, buf.String(), `"operation":"are_friends"`)
+
+	// Publishing is not database work.
+	buf.Reset()
+	require.NoError(t, slow.AcceptRequest(ctx, 1, 2))
+	require.Empty(t, buf.String())
+}
+
+type stubInviteRepository struct {
+	InviteRepository
+	err error
+}
+
+func (s stubInviteRepository) Redeem(context.Context, string, int64) (*models.Invite, error) {
+	return nil, s.err
+}
+
+func TestInstrumentedInviteRepositoryRecordsRejections(t *testing.T) {
+	repo := NewInstrumentedInviteRepository(stubInviteRepository{err: ErrInviteUsed}, 0)
+	before := queryCount(t, "redeem_invite", "rejected")
+	_, err := repo.Redeem(context.Background(), "inv", 2)
+	require.ErrorIs(t, err, ErrInviteUsed)
+	require.Equal(t, before+1, queryCount(t, "redeem_invite", "rejected"))
+}
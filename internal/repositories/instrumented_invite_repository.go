@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"user-service/internal/models"
+)
+
+// instrumentedInviteRepository records InviteRepository calls the same way
+// instrumentedFriendRepository does.
+type instrumentedInviteRepository struct {
+	next          InviteRepository
+	slowThreshold time.Duration
+}
+
+// NewInstrumentedInviteRepository wraps next with query metrics. A zero
+// slowThreshold disables the slow query log.
+func NewInstrumentedInviteRepository(next InviteRepository, slowThreshold time.Duration) InviteRepository {
+	return &instrumentedInviteRepository{next: next, slowThreshold: slowThreshold}
+}
+
+func (r *instrumentedInviteRepository) Create(ctx context.Context, inviterID int64, expiresAt time.Time, singleUse bool) (*models.Invite, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.Create(ctx, inviterID, expiresAt, singleUse)
+	observeQuery(ctx, "create_invite", timer, err, r.slowThreshold)
+	return result, err
+}
+
+func (r *instrumentedInviteRepository) ListByInviter(ctx context.Context, inviterID int64) ([]models.Invite, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.ListByInviter(ctx, inviterID)
+	observeQuery(ctx, "list_invites", timer, err, r.slowThreshold)
+	return result, err
+}
+
+func (r *instrumentedInviteRepository) Revoke(ctx context.Context, id string, inviterID int64) error {
+	ctx, timer := startQueryTimer(ctx)
+	err := r.next.Revoke(ctx, id, inviterID)
+	observeQuery(ctx, "revoke_invite", timer, err, r.slowThreshold)
+	return err
+}
+
+func (r *instrumentedInviteRepository) Redeem(ctx context.Context, id string, redeemerID int64) (*models.Invite, error) {
+	ctx, timer := startQueryTimer(ctx)
+	result, err := r.next.Redeem(ctx, id, redeemerID)
+	observeQuery(ctx, "redeem_invite", timer, err, r.slowThreshold)
+	return result, err
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"user-service/internal/events"
+	"user-service/internal/models"
+	"user-service/internal/rabbitmq"
+)
+
+var (
+	ErrInviteExpired  = errors.New("invite has expired")
+	ErrInviteRevoked  = errors.New("invite has been revoked")
+	ErrInviteUsed     = errors.New("single-use invite has already been redeemed")
+	ErrOwnInvite      = errors.New("cannot redeem your own invite")
+	ErrAlreadyFriends = errors.New("users are already friends")
+)
+
+// InviteRepository stores friendship invites. Lookups by ID return
+// sql.ErrNoRows for invites that do not exist or, where an inviter is
+// given, belong to someone else.
+type InviteRepository interface {
+	Create(ctx context.Context, inviterID int64, expiresAt time.Time, singleUse bool) (*models.Invite, error)
+	ListByInviter(ctx context.Context, inviterID int64) ([]models.Invite, error)
+	Revoke(ctx context.Context, id string, inviterID int64) error
+	// Redeem befriends redeemerID with the inviter and counts the use.
+	Redeem(ctx context.Context, id string, redeemerID int64) (*models.Invite, error)
+}
+
+type inviteRepository struct {
+	db        *sqlx.DB
+	publisher rabbitmq.Publisher
+	bus       *events.Bus
+}
+
+// NewInviteRepository creates a repository that announces friendships made
+// through invites the same way NewFriendRepository announces accepted
+// requests.
+func NewInviteRepository(db *sqlx.DB, publisher rabbitmq.Publisher, bus *events.Bus) InviteRepository {
+	return &inviteRepository{db: db, publisher: publisher, bus: bus}
+}
+
+const inviteColumns = `id, inviter_id, single_use, uses, expires_at, revoked_at, created_at`
+
+func (r *inviteRepository) Create(ctx context.Context, inviterID int64, expiresAt time.Time, singleUse bool) (*models.Invite, error) {
+	var invite models.Invite
+	err := r.db.QueryRowxContext(ctx, `
+INSERT INTO friend_invites (id, inviter_id, single_use, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING `+inviteColumns, uuid.NewString(), inviterID, singleUse, expiresAt).StructScan(&invite)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) ListByInviter(ctx context.Context, inviterID int64) ([]models.Invite, error) {
+	var invites []models.Invite
+	err := r.db.SelectContext(ctx, &invites, `
+SELECT `+inviteColumns+` FROM friend_invites
+WHERE inviter_id=$1
+ORDER BY created_at DESC, id
+`, inviterID)
+	return invites, err
+}
+
+// Revoke is idempotent; revoking twice keeps the first revocation time.
+func (r *inviteRepository) Revoke(ctx context.Context, id string, inviterID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+UPDATE friend_invites SET revoked_at=COALESCE(revoked_at, NOW())
+WHERE id=$1 AND inviter_id=$2
+`, id, inviterID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Redeem locks the invite so concurrent redemptions of a single-use invite
+// cannot both succeed. Invites of a user deleted from the projection count
+// as revoked, even if DeleteUserData has not run for them yet. Pending or
+// held requests between the two users are marked accepted, as the
+// friendship now exists.
+func (r *inviteRepository) Redeem(ctx context.Context, id string, redeemerID int64) (*models.Invite, error) {
+	var invite models.Invite
+	var redeemedAt time.Time
+	err := withTx(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &invite, `
+SELECT `+inviteColumns+` FROM friend_invites WHERE id=$1 FOR UPDATE
+`, id); err != nil {
+			return err
+		}
+
+		redeemedAt = time.Now().UTC()
+		switch {
+		case invite.RevokedAt != nil:
+			return ErrInviteRevoked
+		case !invite.ExpiresAt.After(redeemedAt):
+			return ErrInviteExpired
+		case invite.SingleUse && invite.Uses > 0:
+			return ErrInviteUsed
+		case invite.InviterID == redeemerID:
+			return ErrOwnInvite
+		}
+
+		var inviterDeleted bool
+		if err := tx.GetContext(ctx, &inviterDeleted, `
+SELECT EXISTS (SELECT 1 FROM users WHERE id=$1 AND deleted_at IS NOT NULL)
+`, invite.InviterID); err != nil {
+			return err
+		}
+		if inviterDeleted {
+			return ErrInviteRevoked
+		}
+
+		var friends bool
+		if err := tx.GetContext(ctx, &friends, `
+SELECT EXISTS (SELECT 1 FROM friendships WHERE user_id=$1 AND friend_id=$2)
+`, invite.InviterID, redeemerID); err != nil {
+			return err
+		}
+		if friends {
+			return ErrAlreadyFriends
+		}
+
+		if err := tx.GetContext(ctx, &invite.Uses, `
+UPDATE friend_invites SET uses=uses+1 WHERE id=$1 RETURNING uses
+`, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE friend_requests SET status='accepted'
+WHERE status IN ('pending','held')
+AND ((from_user_id=$1 AND to_user_id=$2) OR (from_user_id=$2 AND to_user_id=$1))
+`, invite.InviterID, redeemerID); err != nil {
+			return err
+		}
+		if err := insertFriendship(ctx, tx, invite.InviterID, redeemerID); err != nil {
+			return err
+		}
+		return insertFriendship(ctx, tx, redeemerID, invite.InviterID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logPublish(ctx, r.publisher, "friendship.created", map[string]any{
+		"user_id":     invite.InviterID,
+		"friend_id":   redeemerID,
+		"accepted_at": redeemedAt,
+		"source":      "invite",
+		"invite_id":   invite.ID,
+	})
+	r.bus.Publish(pairEvents(events.FriendshipCreated, invite.InviterID, redeemerID, 0, redeemedAt)...)
+
+	return &invite, nil
+}
@@ -2,14 +2,24 @@ package repositories
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
 	"user-service/internal/models"
 )
 
+// UserRepository reads and maintains the local projection of auth-service
+// users. Writes carry the time of the change they apply so a late event
+// cannot overwrite newer state.
 type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) ([]models.User, error)
+	Upsert(ctx context.Context, user models.User, syncedAt time.Time) error
+	MarkDeleted(ctx context.Context, id int64, syncedAt time.Time) error
+	ReferencedUserIDs(ctx context.Context) ([]int64, error)
+	Search(ctx context.Context, viewerID int64, query string, limit, offset int) ([]models.UserSearchResult, error)
 }
 
 type userRepository struct {
@@ -20,11 +30,100 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+// GetByID returns sql.ErrNoRows for unknown and deleted users.
 func (r *userRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
 	var user models.User
-	err := r.db.GetContext(ctx, &user, "SELECT id, username FROM users WHERE id=$1", id)
+	err := r.db.GetContext(ctx, &user, `
+SELECT id, username, created_at FROM users WHERE id=$1 AND deleted_at IS NULL
+`, id)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+// GetByUsername returns every live user whose username equals username
+// ignoring case.
+func (r *userRepository) GetByUsername(ctx context.Context, username string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.SelectContext(ctx, &users, `
+SELECT id, username, created_at FROM users
+WHERE lower(username)=lower($1) AND deleted_at IS NULL
+ORDER BY id
+`, username)
+	return users, err
+}
+
+func (r *userRepository) Upsert(ctx context.Context, user models.User, syncedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO users (id, username, created_at, synced_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (id) DO UPDATE SET
+username=EXCLUDED.username,
+created_at=COALESCE(EXCLUDED.created_at, users.created_at),
+deleted_at=NULL,
+synced_at=EXCLUDED.synced_at
+WHERE users.synced_at <= EXCLUDED.synced_at
+`, user.ID, user.Username, user.CreatedAt, syncedAt)
+	return err
+}
+
+// MarkDeleted leaves a tombstone, even for a user never seen before, so an
+// older created or updated event arriving later is ignored.
+func (r *userRepository) MarkDeleted(ctx context.Context, id int64, syncedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO users (id, username, deleted_at, synced_at)
+VALUES ($1, '', $2, $2)
+ON CONFLICT (id) DO UPDATE SET
+deleted_at=EXCLUDED.deleted_at,
+synced_at=EXCLUDED.synced_at
+WHERE users.synced_at <= EXCLUDED.synced_at
+`, id, syncedAt)
+	return err
+}
+
+// ReferencedUserIDs lists every user that appears in a friend request or
+// friendship.
+func (r *userRepository) ReferencedUserIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	err := r.db.SelectContext(ctx, &ids, `
+SELECT from_user_id FROM friend_requests
+UNION SELECT to_user_id FROM friend_requests
+UNION SELECT user_id FROM friendships
+ORDER BY 1
+`)
+	return ids, err
+}
+
+// likeEscaper makes LIKE treat a search query literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search finds users whose username starts with query or is similar to it,
+// leaving out viewerID. Prefix matches come first, then users sharing the
+// most friends with the viewer, then the closest trigram matches.
+func (r *userRepository) Search(ctx context.Context, viewerID int64, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	query = strings.ToLower(query)
+	users := []models.UserSearchResult{}
+	err := r.db.SelectContext(ctx, &users, `
+WITH friends AS (
+SELECT friend_id FROM friendships WHERE user_id=$1
+), mutuals AS (
+SELECT f.friend_id AS user_id, COUNT(*) AS mutual_friends
+FROM friendships f JOIN friends ON friends.friend_id = f.user_id
+GROUP BY f.friend_id
+)
+SELECT u.id, u.username,
+EXISTS (SELECT 1 FROM friends WHERE friends.friend_id = u.id) AS is_friend,
+COALESCE(m.mutual_friends, 0) AS mutual_friends
+FROM users u
+LEFT JOIN mutuals m ON m.user_id = u.id
+WHERE u.deleted_at IS NULL AND u.id<>$1
+AND (lower(u.username) LIKE $3 ESCAPE '\' OR lower(u.username) % $2)
+ORDER BY lower(u.username) LIKE $3 ESCAPE '\' DESC,
+mutual_friends DESC,
+similarity(lower(u.username), $2) DESC,
+u.username, u.id
+LIMIT $4 OFFSET $5
+`, viewerID, query, likeEscaper.Replace(query)+"%", limit, offset)
+	return users, err
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"user-service/internal/handlers"
+	"user-service/internal/middleware"
+	"user-service/internal/openapi"
+	"user-service/internal/ratelimit"
+)
+
+// Handlers are the HTTP handlers served by the API.
+type Handlers struct {
+	Users   *handlers.UserHandler
+	Friends *handlers.FriendHandler
+	Admin   *handlers.AdminHandler
+	Events  *handlers.EventStreamHandler
+	Exports *handlers.ExportHandler
+	// Invites may be nil when invite links are not configured.
+	Invites *handlers.InviteHandler
+	// SearchDisabled drops /users/search when the database cannot serve it.
+	SearchDisabled bool
+}
+
+// Limits are the per-route rate limits.
+type Limits struct {
+	FriendRequest   ratelimit.Limit
+	FriendResponse  ratelimit.Limit
+	AnonymousLookup ratelimit.Limit
+}
+
+// Middleware holds the per-route middleware. A nil RateLimiter disables
+// rate limiting and a nil Idempotent skips Idempotency-Key handling.
+type Middleware struct {
+	Auth        []gin.HandlerFunc
+	Idempotent  gin.HandlerFunc
+	RateLimiter *middleware.RateLimiter
+	Limits      Limits
+}
+
+// Legacy controls the unversioned aliases of the /v1 routes. DeprecatedAt is
+// when they were deprecated in favour of /v1; a zero Sunset omits the Sunset
+// header.
+type Legacy struct {
+	Enabled      bool
+	DeprecatedAt time.Time
+	Sunset       time.Time
+}
+
+// Register adds every route of the service to r. Operational endpoints live
+// at the root and the API under a version prefix; a later version gets its
+// own registerVN with its own handlers, mounted next to /v1. Each route must
+// be described in the OpenAPI document; router_test.go enforces it.
+func Register(r *gin.Engine, h Handlers, mw Middleware, legacy Legacy) {
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/openapi.json", openapi.ServeSpec)
+	r.GET("/docs", openapi.ServeDocs)
+
+	registerV1(r.Group("/v1"), h, mw)
+	if legacy.Enabled {
+		registerV1(r.Group("", middleware.Deprecated(legacy.DeprecatedAt, legacy.Sunset, "/v1")), h, mw)
+	}
+}
+
+func registerV1(api *gin.RouterGroup, h Handlers, mw Middleware) {
+	idempotent := mw.Idempotent
+	if idempotent == nil {
+		idempotent = func(c *gin.Context) { c.Next() }
+	}
+	limit := mw.RateLimiter.Limit
+
+	api.GET("/users/:id", limit("user_lookup", mw.Limits.AnonymousLookup), h.Users.GetUserByID)
+
+	auth := api.Group("", mw.Auth...)
+	auth.GET("/users/me", h.Users.GetMe)
+	if !h.SearchDisabled {
+		auth.GET("/users/search", h.Users.SearchUsers)
+	}
+	auth.GET("/users/me/export", h.Exports.Export)
+	auth.GET("/users/me/export/:id", h.Exports.Status)
+	auth.GET("/users/me/export/:id/download", h.Exports.Download)
+	auth.POST("/friends/request", idempotent, limit("friend_request", mw.Limits.FriendRequest), h.Friends.SendRequest)
+	auth.GET("/friends/requests/incoming", h.Friends.ListIncoming)
+	auth.POST("/friends/requests/:id/accept", idempotent, limit("friend_response", mw.Limits.FriendResponse), h.Friends.AcceptRequest)
+	auth.POST("/friends/requests/:id/reject", idempotent, limit("friend_response", mw.Limits.FriendResponse), h.Friends.RejectRequest)
+	auth.GET("/friends", h.Friends.ListFriends)
+	auth.GET("/events/stream", h.Events.Stream)
+	if h.Invites != nil {
+		auth.POST("/friends/invites", idempotent, limit("friend_request", mw.Limits.FriendRequest), h.Invites.Create)
+		auth.GET("/friends/invites", h.Invites.List)
+		auth.DELETE("/friends/invites/:id", h.Invites.Revoke)
+		auth.POST("/friends/invites/:token/redeem", idempotent, limit("friend_response", mw.Limits.FriendResponse), h.Invites.Redeem)
+	}
+
+	admin := auth.Group("/admin", middleware.RequireRole("admin"))
+	admin.GET("/users/:id/friends", h.Admin.ListFriends)
+	admin.GET("/users/:id/requests", h.Admin.ListRequests)
+	admin.DELETE("/users/:id/friends/:friendId", h.Admin.RemoveFriendship)
+	admin.POST("/users/:id/requests/purge", h.Admin.PurgePendingRequests)
+	admin.GET("/requests/held", h.Admin.ListHeldRequests)
+	admin.POST("/requests/:id/release", h.Admin.ReleaseHeldRequest)
+	admin.POST("/requests/:id/discard", h.Admin.DiscardHeldRequest)
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/handlers"
+	"user-service/internal/openapi"
+)
+
+var ginParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+func newTestEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	Register(r, Handlers{Invites: &handlers.InviteHandler{}}, Middleware{}, Legacy{
+		Enabled:      true,
+		DeprecatedAt: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+	})
+	return r
+}
+
+func specOperations(t *testing.T) map[string]bool {
+	t.Helper()
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openapi.Spec, &spec))
+
+	ops := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for method := range item {
+			ops[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	return ops
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	ops := specOperations(t)
+	routes := make(map[string]bool)
+	for _, route := range newTestEngine().Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		op := route.Method + " " + path
+		if !ops[op] && !strings.HasPrefix(path, "/v1/") {
+			// Unversioned aliases are documented through their /v1 route.
+			op = route.Method + " /v1" + path
+		}
+		routes[op] = true
+		require.True(t, ops[op], "route %s is missing from internal/openapi/openapi.json", op)
+	}
+	for op := range ops {
+		require.True(t, routes[op], "openapi.json documents %s but no such route is registered", op)
+	}
+}
+
+func TestServesSpec(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestEngine().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, string(openapi.Spec), rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	newTestEngine().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "openapi.json")
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r := newTestEngine()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "@1792281600", rec.Header().Get("Deprecation"))
+	require.Equal(t, "Fri, 30 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+	require.Equal(t, `</v1/users/abc>; rel="successor-version"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/abc", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Empty(t, rec.Header().Get("Deprecation"))
+
+	gin.SetMode(gin.TestMode)
+	withoutLegacy := gin.New()
+	Register(withoutLegacy, Handlers{}, Middleware{}, Legacy{})
+	rec = httptest.NewRecorder()
+	withoutLegacy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidInviteToken is returned for tokens that are malformed or were
+// not signed with the service's secret.
+var ErrInvalidInviteToken = errors.New("invalid invite token")
+
+// InviteTokens signs and verifies invite tokens of the form
+// "<invite id>.<expiry unix>.<signature>". The signature lets forged or
+// tampered tokens be rejected without a database lookup; the invite row
+// stays authoritative for revocation and usage.
+type InviteTokens struct {
+	secret []byte
+}
+
+func NewInviteTokens(secret string) (*InviteTokens, error) {
+	if secret == "" {
+		return nil, errors.New("invite secret is required")
+	}
+	return &InviteTokens{secret: []byte(secret)}, nil
+}
+
+func (t *InviteTokens) Sign(id string, expiresAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + t.signature(payload)
+}
+
+// Parse returns the invite ID and expiry carried by a validly signed token.
+// The expiry is not checked here.
+func (t *InviteTokens) Parse(token string) (string, time.Time, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", time.Time{}, ErrInvalidInviteToken
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(t.signature(payload))) {
+		return "", time.Time{}, ErrInvalidInviteToken
+	}
+
+	id, expiry, ok := strings.Cut(payload, ".")
+	if !ok || id == "" {
+		return "", time.Time{}, ErrInvalidInviteToken
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidInviteToken
+	}
+	return id, time.Unix(unix, 0), nil
+}
+
+func (t *InviteTokens) signature(payload string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteTokensRoundTrip(t *testing.T) {
+	tokens, err := NewInviteTokens("secret")
+	require.NoError(t, err)
+	expiresAt := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	id, parsedExpiry, err := tokens.Parse(tokens.Sign("invite-1", expiresAt))
+	require.NoError(t, err)
+	require.Equal(t, "invite-1", id)
+	require.True(t, expiresAt.Equal(parsedExpiry))
+}
+
+func TestInviteTokensRejectTampering(t *testing.T) {
+	tokens, err := NewInviteTokens("secret")
+	require.NoError(t, err)
+	other, err := NewInviteTokens("other-secret")
+	require.NoError(t, err)
+	expiresAt := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+	token := tokens.Sign("invite-1", expiresAt)
+	unix := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	for _, bad := range []string{
+		"",
+		"invite-1",
+		token[:len(token)-1],
+		"invite-2" + token[len("invite-1"):],
+		strings.Replace(token, "."+unix+".", "."+strconv.FormatInt(expiresAt.Unix()+3600, 10)+".", 1),
+		other.Sign("invite-1", expiresAt),
+	} {
+		_, _, err := tokens.Parse(bad)
+		require.ErrorIs(t, err, ErrInvalidInviteToken, bad)
+	}
+}
+
+func TestNewInviteTokensRequiresSecret(t *testing.T) {
+	_, err := NewInviteTokens("")
+	require.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// RequestHistory is the part of the friend repository the request policy
+// reads from.
+type RequestHistory interface {
+	CountRequestsSince(ctx context.Context, fromUserID int64, since time.Time) (int64, error)
+	RequestOutcomesSince(ctx context.Context, fromUserID int64, since time.Time) (accepted, rejected int64, err error)
+}
+
+// RequestPolicyOptions configures the daily quota and the rejection-ratio
+// heuristic. A zero DailyLimit or MaxRejectionRatio disables that check.
+type RequestPolicyOptions struct {
+	DailyLimit int
+	// Window is how far back sent requests are scored.
+	Window time.Duration
+	// MinDecisions is how many answered requests a sender needs before the
+	// ratio is trusted, so one early rejection does not hold a new account.
+	MinDecisions int
+	// MaxRejectionRatio is the share of rejected answers above which new
+	// requests are held for review.
+	MaxRejectionRatio float64
+}
+
+// RequestVerdict is the policy's answer for one new friend request.
+type RequestVerdict struct {
+	QuotaExceeded  bool
+	Hold           bool
+	RejectionRatio float64
+}
+
+// RequestPolicy decides whether a sender may create another friend request
+// and whether it should be held for moderator review.
+type RequestPolicy struct {
+	history RequestHistory
+	opts    RequestPolicyOptions
+	now     func() time.Time
+}
+
+func NewRequestPolicy(history RequestHistory, opts RequestPolicyOptions) *RequestPolicy {
+	return &RequestPolicy{history: history, opts: opts, now: time.Now}
+}
+
+func (p *RequestPolicy) Evaluate(ctx context.Context, fromUserID int64) (RequestVerdict, error) {
+	var verdict RequestVerdict
+	now := p.now()
+
+	if p.opts.DailyLimit > 0 {
+		sent, err := p.history.CountRequestsSince(ctx, fromUserID, now.Add(-24*time.Hour))
+		if err != nil {
+			return verdict, err
+		}
+		if sent >= int64(p.opts.DailyLimit) {
+			verdict.QuotaExceeded = true
+			return verdict, nil
+		}
+	}
+
+	if p.opts.MaxRejectionRatio > 0 {
+		accepted, rejected, err := p.history.RequestOutcomesSince(ctx, fromUserID, now.Add(-p.opts.Window))
+		if err != nil {
+			return verdict, err
+		}
+		decided := accepted + rejected
+		if decided > 0 {
+			verdict.RejectionRatio = float64(rejected) / float64(decided)
+		}
+		verdict.Hold = decided >= int64(p.opts.MinDecisions) && verdict.RejectionRatio > p.opts.MaxRejectionRatio
+	}
+	return verdict, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"user-service/internal/mocks"
+)
+
+func newTestPolicy(history *mocks.MockFriendRepository) *RequestPolicy {
+	policy := NewRequestPolicy(history, RequestPolicyOptions{
+		DailyLimit:        20,
+		Window:            7 * 24 * time.Hour,
+		MinDecisions:      10,
+		MaxRejectionRatio: 0.8,
+	})
+	now := time.Unix(1700000000, 0)
+	policy.now = func() time.Time { return now }
+	return policy
+}
+
+func TestRequestPolicyQuotaExceeded(t *testing.T) {
+	t.Parallel()
+
+	history := new(mocks.MockFriendRepository)
+	policy := newTestPolicy(history)
+	history.On("CountRequestsSince", mock.Anything, int64(1), policy.now().Add(-24*time.Hour)).Return(int64(20), nil).Once()
+
+	verdict, err := policy.Evaluate(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, verdict.QuotaExceeded)
+	history.AssertExpectations(t)
+}
+
+func TestRequestPolicyHoldsFrequentlyRejectedSenders(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name               string
+		accepted, rejected int64
+		hold               bool
+	}{
+		{"mostly rejected", 1, 9, true},
+		{"too few answers", 0, 5, false},
+		{"mostly accepted", 8, 4, false},
+	}
+	for _, tc := range cases {
+		history := new(mocks.MockFriendRepository)
+		policy := newTestPolicy(history)
+		history.On("CountRequestsSince", mock.Anything, int64(1), mock.Anything).Return(int64(3), nil)
+		history.On("RequestOutcomesSince", mock.Anything, int64(1), policy.now().Add(-7*24*time.Hour)).Return(tc.accepted, tc.rejected, nil)
+
+		verdict, err := policy.Evaluate(context.Background(), 1)
+		require.NoError(t, err, tc.name)
+		require.False(t, verdict.QuotaExceeded, tc.name)
+		require.Equal(t, tc.hold, verdict.Hold, tc.name)
+	}
+}
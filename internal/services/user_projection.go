@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-service/internal/logging"
+	"user-service/internal/models"
+)
+
+// Routing keys of the auth-service user events.
+const (
+	UserCreatedEvent = "user.created"
+	UserUpdatedEvent = "user.updated"
+	UserDeletedEvent = "user.deleted"
+)
+
+// UserEvent is the body of an auth-service user event. OccurredAt orders
+// events for the same user; CreatedAt is RFC 3339 like GetUserResponse.
+type UserEvent struct {
+	UserID     int64     `json:"user_id"`
+	Username   string    `json:"username"`
+	CreatedAt  string    `json:"created_at"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UserProjectionStore is the write side of the local user projection.
+type UserProjectionStore interface {
+	Upsert(ctx context.Context, user models.User, syncedAt time.Time) error
+	MarkDeleted(ctx context.Context, id int64, syncedAt time.Time) error
+}
+
+// UserDataCleaner removes what this service holds about a deleted user.
+type UserDataCleaner interface {
+	DeleteUserData(ctx context.Context, userID int64) (models.DeletedUserData, error)
+}
+
+// UserProjection keeps the local users table in step with auth-service.
+type UserProjection struct {
+	store   UserProjectionStore
+	cleaner UserDataCleaner
+	now     func() time.Time
+}
+
+// NewUserProjection creates the projection. When cleaner is non-nil, a
+// user.deleted event also removes the user's friendships and requests.
+func NewUserProjection(store UserProjectionStore, cleaner UserDataCleaner) *UserProjection {
+	return &UserProjection{store: store, cleaner: cleaner, now: time.Now}
+}
+
+// HandleEvent applies one auth-service user event. Unknown routing keys are
+// ignored.
+func (p *UserProjection) HandleEvent(ctx context.Context, routingKey string, body []byte) error {
+	switch routingKey {
+	case UserCreatedEvent, UserUpdatedEvent, UserDeletedEvent:
+	default:
+		return nil
+	}
+
+	var event UserEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", routingKey, err)
+	}
+	if event.UserID <= 0 {
+		return fmt.Errorf("%s event has no user_id", routingKey)
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = p.now()
+	}
+
+	if routingKey == UserDeletedEvent {
+		return p.deleteUser(ctx, event)
+	}
+	return p.store.Upsert(ctx, models.User{
+		ID:        event.UserID,
+		Username:  event.Username,
+		CreatedAt: parseCreatedAt(event.CreatedAt),
+	}, event.OccurredAt)
+}
+
+// deleteUser tombstones the user and then cascades. Both steps are
+// idempotent, so a redelivered event after a partial failure finishes the
+// job.
+func (p *UserProjection) deleteUser(ctx context.Context, event UserEvent) error {
+	if err := p.store.MarkDeleted(ctx, event.UserID, event.OccurredAt); err != nil {
+		return err
+	}
+	if p.cleaner == nil {
+		return nil
+	}
+	deleted, err := p.cleaner.DeleteUserData(ctx, event.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete data of user %d: %w", event.UserID, err)
+	}
+	logging.FromContext(ctx).Info("deleted user data",
+		"user_id", event.UserID,
+		"friendships", len(deleted.FriendIDs),
+		"requests", deleted.Requests,
+	)
+	return nil
+}
+
+// BackfillResult counts what Backfill did.
+type BackfillResult struct {
+	Synced  int
+	Deleted int
+	Failed  int
+}
+
+// Backfill copies the given users from auth-service into the projection.
+// Users auth-service no longer knows are deleted the same way a
+// user.deleted event deletes them; other lookup failures are logged and
+// counted so one bad user does not stop the run.
+func (p *UserProjection) Backfill(ctx context.Context, auth AuthClient, ids []int64) (BackfillResult, error) {
+	var result BackfillResult
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		fetchedAt := p.now()
+		user, err := auth.GetUser(ctx, id)
+		switch {
+		case status.Code(err) == codes.NotFound:
+			if err := p.deleteUser(ctx, UserEvent{UserID: id, OccurredAt: fetchedAt}); err != nil {
+				return result, err
+			}
+			result.Deleted++
+			continue
+		case err != nil:
+			logging.FromContext(ctx).Warn("failed to fetch user for backfill", "user_id", id, "error", err)
+			result.Failed++
+			continue
+		}
+
+		if err := p.store.Upsert(ctx, models.User{
+			ID:        user.Id,
+			Username:  user.Username,
+			CreatedAt: parseCreatedAt(user.CreatedAt),
+		}, fetchedAt); err != nil {
+			return result, err
+		}
+		result.Synced++
+	}
+	return result, nil
+}
+
+func parseCreatedAt(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user-service/internal/mocks"
+	"user-service/internal/models"
+	authpb "user-service/proto/auth"
+)
+
+func TestUserProjectionAppliesEvents(t *testing.T) {
+	t.Parallel()
+
+	store := new(mocks.MockUserRepository)
+	projection := NewUserProjection(store, nil)
+
+	occurredAt := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.On("Upsert", mock.Anything, models.User{ID: 7, Username: "ann", CreatedAt: &createdAt}, occurredAt).Return(nil).Once()
+	store.On("MarkDeleted", mock.Anything, int64(7), occurredAt).Return(nil).Once()
+
+	require.NoError(t, projection.HandleEvent(context.Background(), UserUpdatedEvent,
+		[]byte(`{"user_id":7,"username":"ann","created_at":"2024-03-01T12:00:00Z","occurred_at":"2024-03-02T08:00:00Z"}`)))
+	require.NoError(t, projection.HandleEvent(context.Background(), UserDeletedEvent,
+		[]byte(`{"user_id":7,"occurred_at":"2024-03-02T08:00:00Z"}`)))
+	require.NoError(t, projection.HandleEvent(context.Background(), "user.logged_in", []byte(`not json`)))
+
+	store.AssertExpectations(t)
+}
+
+func TestUserProjectionCascadesDeletes(t *testing.T) {
+	t.Parallel()
+
+	store := new(mocks.MockUserRepository)
+	friends := new(mocks.MockFriendRepository)
+	projection := NewUserProjection(store, friends)
+
+	body := []byte(`{"user_id":7,"occurred_at":"2024-03-02T08:00:00Z"}`)
+	store.On("MarkDeleted", mock.Anything, int64(7), mock.Anything).Return(nil).Twice()
+	friends.On("DeleteUserData", mock.Anything, int64(7)).Return(models.DeletedUserData{}, errors.New("deadlock")).Once()
+	friends.On("DeleteUserData", mock.Anything, int64(7)).Return(models.DeletedUserData{FriendIDs: []int64{8}}, nil).Once()
+
+	// The failed first delivery is requeued and the redelivery completes.
+	require.Error(t, projection.HandleEvent(context.Background(), UserDeletedEvent, body))
+	require.NoError(t, projection.HandleEvent(context.Background(), UserDeletedEvent, body))
+
+	store.AssertExpectations(t)
+	friends.AssertExpectations(t)
+}
+
+func TestUserProjectionRejectsMalformedEvents(t *testing.T) {
+	t.Parallel()
+
+	projection := NewUserProjection(new(mocks.MockUserRepository), nil)
+
+	require.Error(t, projection.HandleEvent(context.Background(), UserCreatedEvent, []byte(`{`)))
+	require.ErrorContains(t, projection.HandleEvent(context.Background(), UserCreatedEvent, []byte(`{"username":"ann"}`)), "user_id")
+}
+
+func TestUserProjectionBackfill(t *testing.T) {
+	t.Parallel()
+
+	store := new(mocks.MockUserRepository)
+	auth := new(mocks.MockAuthClient)
+	friends := new(mocks.MockFriendRepository)
+	projection := NewUserProjection(store, friends)
+	now := time.Unix(1700000000, 0)
+	projection.now = func() time.Time { return now }
+
+	auth.On("GetUser", mock.Anything, int64(1)).Return(&authpb.GetUserResponse{Id: 1, Username: "ann", CreatedAt: "bad"}, nil).Once()
+	auth.On("GetUser", mock.Anything, int64(2)).Return(nil, status.Error(codes.NotFound, "gone")).Once()
+	auth.On("GetUser", mock.Anything, int64(3)).Return(nil, errors.New("timeout")).Once()
+	store.On("Upsert", mock.Anything, models.User{ID: 1, Username: "ann"}, now).Return(nil).Once()
+	store.On("MarkDeleted", mock.Anything, int64(2), now).Return(nil).Once()
+	// Tombstoned users lose their data as with a user.deleted event.
+	friends.On("DeleteUserData", mock.Anything, int64(2)).Return(models.DeletedUserData{FriendIDs: []int64{1}}, nil).Once()
+
+	result, err := projection.Backfill(context.Background(), auth, []int64{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, BackfillResult{Synced: 1, Deleted: 1, Failed: 1}, result)
+
+	store.AssertExpectations(t)
+	auth.AssertExpectations(t)
+	friends.AssertExpectations(t)
+}
@@ -2,17 +2,34 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
+	"user-service/internal/logging"
+	"user-service/internal/models"
 	authpb "user-service/proto/auth"
 )
 
 // AuthClient describes the subset of the auth gRPC client used by the service.
 type AuthClient interface {
 	GetUser(ctx context.Context, userID int64) (*authpb.GetUserResponse, error)
+	GetUserByUsername(ctx context.Context, username string) (*authpb.GetUserResponse, error)
 }
 
+// UserStore is the local user projection the service reads first.
+type UserStore interface {
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) ([]models.User, error)
+}
+
+// ErrAmbiguousUsername is returned when a username matches several users
+// case-insensitively and none of them exactly.
+var ErrAmbiguousUsername = errors.New("username matches more than one user")
+
 type UserService struct {
 	authClient AuthClient
+	users      UserStore
 }
 
 type UserDTO struct {
@@ -21,14 +38,66 @@ type UserDTO struct {
 	CreatedAt string `json:"created_at,omitempty"`
 }
 
-func NewUserService(authClient AuthClient) *UserService {
-	return &UserService{authClient: authClient}
+// NewUserService creates a service that looks users up in the local
+// projection and falls back to auth-service for users it does not hold.
+// users may be nil to always ask auth-service.
+func NewUserService(authClient AuthClient, users UserStore) *UserService {
+	return &UserService{authClient: authClient, users: users}
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id int64) (*UserDTO, error) {
+	if s.users != nil {
+		user, err := s.users.GetByID(ctx, id)
+		if err == nil {
+			return userDTO(user), nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(ctx).Warn("user projection lookup failed; asking auth-service", "user_id", id, "error", err)
+		}
+	}
+
 	user, err := s.authClient.GetUser(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	return &UserDTO{ID: user.Id, Username: user.Username, CreatedAt: user.CreatedAt}, nil
 }
+
+// GetUserByUsername resolves a username, ignoring case in the local
+// projection. An exact match wins over other case variants; several variants
+// without one give ErrAmbiguousUsername. Names the projection does not hold
+// are looked up in auth-service, which answers NotFound for unknown ones.
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*UserDTO, error) {
+	if s.users != nil {
+		matches, err := s.users.GetByUsername(ctx, username)
+		if err != nil {
+			logging.FromContext(ctx).Warn("user projection lookup failed; asking auth-service", "username", username, "error", err)
+		}
+		for i := range matches {
+			if matches[i].Username == username {
+				return userDTO(&matches[i]), nil
+			}
+		}
+		switch len(matches) {
+		case 0:
+		case 1:
+			return userDTO(&matches[0]), nil
+		default:
+			return nil, ErrAmbiguousUsername
+		}
+	}
+
+	user, err := s.authClient.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &UserDTO{ID: user.Id, Username: user.Username, CreatedAt: user.CreatedAt}, nil
+}
+
+func userDTO(user *models.User) *UserDTO {
+	dto := &UserDTO{ID: user.ID, Username: user.Username}
+	if user.CreatedAt != nil {
+		dto.CreatedAt = user.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
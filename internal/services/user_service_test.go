@@ -2,13 +2,16 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"user-service/internal/mocks"
+	"user-service/internal/models"
 	authpb "user-service/proto/auth"
 )
 
@@ -16,7 +19,7 @@ func TestGetUserByIDSuccess(t *testing.T) {
 	t.Parallel()
 
 	mockAuth := new(mocks.MockAuthClient)
-	userSvc := NewUserService(mockAuth)
+	userSvc := NewUserService(mockAuth, nil)
 
 	expectedResp := &authpb.GetUserResponse{Id: 10, Username: "john", CreatedAt: "now"}
 	mockAuth.On("GetUser", mock.Anything, int64(10)).Return(expectedResp, nil).Once()
@@ -34,7 +37,7 @@ func TestGetUserByIDAuthError(t *testing.T) {
 	t.Parallel()
 
 	mockAuth := new(mocks.MockAuthClient)
-	userSvc := NewUserService(mockAuth)
+	userSvc := NewUserService(mockAuth, nil)
 
 	mockErr := errors.New("auth down")
 	mockAuth.On("GetUser", mock.Anything, int64(5)).Return((*authpb.GetUserResponse)(nil), mockErr).Once()
@@ -45,3 +48,85 @@ func TestGetUserByIDAuthError(t *testing.T) {
 
 	mockAuth.AssertExpectations(t)
 }
+
+func TestGetUserByIDPrefersProjection(t *testing.T) {
+	t.Parallel()
+
+	mockAuth := new(mocks.MockAuthClient)
+	users := new(mocks.MockUserRepository)
+	userSvc := NewUserService(mockAuth, users)
+
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	users.On("GetByID", mock.Anything, int64(10)).Return(&models.User{ID: 10, Username: "john", CreatedAt: &createdAt}, nil).Once()
+
+	dto, err := userSvc.GetUserByID(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, &UserDTO{ID: 10, Username: "john", CreatedAt: "2024-03-01T12:00:00Z"}, dto)
+
+	users.AssertExpectations(t)
+	mockAuth.AssertNotCalled(t, "GetUser", mock.Anything, mock.Anything)
+}
+
+func TestGetUserByIDFallsBackToAuth(t *testing.T) {
+	t.Parallel()
+
+	for _, projectionErr := range []error{sql.ErrNoRows, errors.New("db down")} {
+		mockAuth := new(mocks.MockAuthClient)
+		users := new(mocks.MockUserRepository)
+		userSvc := NewUserService(mockAuth, users)
+
+		users.On("GetByID", mock.Anything, int64(10)).Return(nil, projectionErr).Once()
+		mockAuth.On("GetUser", mock.Anything, int64(10)).Return(&authpb.GetUserResponse{Id: 10, Username: "john"}, nil).Once()
+
+		dto, err := userSvc.GetUserByID(context.Background(), 10)
+		require.NoError(t, err)
+		require.Equal(t, "john", dto.Username)
+
+		users.AssertExpectations(t)
+		mockAuth.AssertExpectations(t)
+	}
+}
+
+func TestGetUserByUsernamePrefersExactMatch(t *testing.T) {
+	t.Parallel()
+
+	users := new(mocks.MockUserRepository)
+	userSvc := NewUserService(new(mocks.MockAuthClient), users)
+	users.On("GetByUsername", mock.Anything, "Bob").Return([]models.User{
+		{ID: 2, Username: "bob"},
+		{ID: 3, Username: "Bob"},
+	}, nil).Once()
+
+	dto, err := userSvc.GetUserByUsername(context.Background(), "Bob")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), dto.ID)
+}
+
+func TestGetUserByUsernameAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	users := new(mocks.MockUserRepository)
+	userSvc := NewUserService(new(mocks.MockAuthClient), users)
+	users.On("GetByUsername", mock.Anything, "BOB").Return([]models.User{
+		{ID: 2, Username: "bob"},
+		{ID: 3, Username: "Bob"},
+	}, nil).Once()
+
+	_, err := userSvc.GetUserByUsername(context.Background(), "BOB")
+	require.ErrorIs(t, err, ErrAmbiguousUsername)
+}
+
+func TestGetUserByUsernameFallsBackToAuth(t *testing.T) {
+	t.Parallel()
+
+	mockAuth := new(mocks.MockAuthClient)
+	users := new(mocks.MockUserRepository)
+	userSvc := NewUserService(mockAuth, users)
+	users.On("GetByUsername", mock.Anything, "carol").Return(nil, nil).Once()
+	mockAuth.On("GetUserByUsername", mock.Anything, "carol").Return(&authpb.GetUserResponse{Id: 4, Username: "carol"}, nil).Once()
+
+	dto, err := userSvc.GetUserByUsername(context.Background(), "carol")
+	require.NoError(t, err)
+	require.Equal(t, int64(4), dto.ID)
+	mockAuth.AssertExpectations(t)
+}
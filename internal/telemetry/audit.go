@@ -2,11 +2,11 @@ package telemetry
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
 
+	"user-service/internal/logging"
 	"user-service/internal/rabbitmq"
 )
 
@@ -14,17 +14,21 @@ const AuditRoutingKey = "user-service.audit"
 
 const auditSchemaVersion = 1
 
-// Envelope matches the log-collector audit_log schema.
+// Envelope matches the log-collector audit_log schema. IdentitySource records
+// how UserID was established ("jwt" or "gateway") and TargetUserID is the
+// user acted upon when it differs from UserID, as in admin actions.
 type Envelope struct {
-	SchemaVersion int          `json:"schema_version"`
-	EventID       string       `json:"event_id"`
-	EventType     string       `json:"event_type"`
-	OccurredAt    string       `json:"occurred_at"`
-	Service       string       `json:"service"`
-	Environment   string       `json:"environment"`
-	RequestID     string       `json:"request_id"`
-	UserID        *int64       `json:"user_id,omitempty"`
-	Payload       AuditPayload `json:"payload"`
+	SchemaVersion  int          `json:"schema_version"`
+	EventID        string       `json:"event_id"`
+	EventType      string       `json:"event_type"`
+	OccurredAt     string       `json:"occurred_at"`
+	Service        string       `json:"service"`
+	Environment    string       `json:"environment"`
+	RequestID      string       `json:"request_id"`
+	UserID         *int64       `json:"user_id,omitempty"`
+	IdentitySource string       `json:"identity_source,omitempty"`
+	TargetUserID   *int64       `json:"target_user_id,omitempty"`
+	Payload        AuditPayload `json:"payload"`
 }
 
 // AuditPayload is the payload for audit_log events.
@@ -43,27 +47,43 @@ func NewAuditEmitter(publisher rabbitmq.Publisher, service, environment string)
 	return &AuditEmitter{publisher: publisher, service: service, environment: environment}
 }
 
+// AuditEntry is a single audit record before it is wrapped in an Envelope.
+type AuditEntry struct {
+	Level          string
+	Text           string
+	RequestID      string
+	UserID         *int64
+	IdentitySource string
+	TargetUserID   *int64
+}
+
 func (e *AuditEmitter) EmitAudit(ctx context.Context, level, text, requestID string, userID *int64) {
+	e.Emit(ctx, AuditEntry{Level: level, Text: text, RequestID: requestID, UserID: userID})
+}
+
+func (e *AuditEmitter) Emit(ctx context.Context, entry AuditEntry) {
 	if e == nil || e.publisher == nil {
 		return
 	}
 
 	envelope := Envelope{
-		SchemaVersion: auditSchemaVersion,
-		EventID:       uuid.NewString(),
-		EventType:     "audit_log",
-		OccurredAt:    time.Now().UTC().Format(time.RFC3339Nano),
-		Service:       e.service,
-		Environment:   e.environment,
-		RequestID:     requestID,
-		UserID:        userID,
+		SchemaVersion:  auditSchemaVersion,
+		EventID:        uuid.NewString(),
+		EventType:      "audit_log",
+		OccurredAt:     time.Now().UTC().Format(time.RFC3339Nano),
+		Service:        e.service,
+		Environment:    e.environment,
+		RequestID:      entry.RequestID,
+		UserID:         entry.UserID,
+		IdentitySource: entry.IdentitySource,
+		TargetUserID:   entry.TargetUserID,
 		Payload: AuditPayload{
-			Level: level,
-			Text:  text,
+			Level: entry.Level,
+			Text:  entry.Text,
 		},
 	}
 
 	if err := e.publisher.Publish(ctx, AuditRoutingKey, envelope); err != nil {
-		log.Printf("warning: failed to publish audit log: %v", err)
+		logging.FromContext(ctx).Warn("failed to publish audit log", "error", err)
 	}
 }
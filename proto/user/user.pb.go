@@ -21,6 +21,64 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type FriendshipEventType int32
+
+const (
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_UNSPECIFIED        FriendshipEventType = 0
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_CREATED    FriendshipEventType = 1
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED   FriendshipEventType = 2
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_REQUEST_REJECTED   FriendshipEventType = 3
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED FriendshipEventType = 4
+	FriendshipEventType_FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_REMOVED FriendshipEventType = 5
+)
+
+// Enum value maps for FriendshipEventType.
+var (
+	FriendshipEventType_name = map[int32]string{
+		0: "FRIENDSHIP_EVENT_TYPE_UNSPECIFIED",
+		1: "FRIENDSHIP_EVENT_TYPE_REQUEST_CREATED",
+		2: "FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED",
+		3: "FRIENDSHIP_EVENT_TYPE_REQUEST_REJECTED",
+		4: "FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED",
+		5: "FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_REMOVED",
+	}
+	FriendshipEventType_value = map[string]int32{
+		"FRIENDSHIP_EVENT_TYPE_UNSPECIFIED":        0,
+		"FRIENDSHIP_EVENT_TYPE_REQUEST_CREATED":    1,
+		"FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED":   2,
+		"FRIENDSHIP_EVENT_TYPE_REQUEST_REJECTED":   3,
+		"FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED": 4,
+		"FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_REMOVED": 5,
+	}
+)
+
+func (x FriendshipEventType) Enum() *FriendshipEventType {
+	p := new(FriendshipEventType)
+	*p = x
+	return p
+}
+
+func (x FriendshipEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FriendshipEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_user_proto_enumTypes[0].Descriptor()
+}
+
+func (FriendshipEventType) Type() protoreflect.EnumType {
+	return &file_proto_user_user_proto_enumTypes[0]
+}
+
+func (x FriendshipEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FriendshipEventType.Descriptor instead.
+func (FriendshipEventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{0}
+}
+
 type AreFriendsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -266,8 +324,13 @@ func (x *BulkUsersRequest) GetIds() []int64 {
 }
 
 type BulkUsersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Users         []*GetUserResponse     `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found users in the order their ids were first requested.
+	Users []*GetUserResponse `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Ids that auth-service reported as not found.
+	MissingIds []int64 `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	// Ids that could not be fetched for any other reason.
+	Errors        []*BulkUserError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -309,33 +372,852 @@ func (x *BulkUsersResponse) GetUsers() []*GetUserResponse {
 	return nil
 }
 
-var File_proto_user_user_proto protoreflect.FileDescriptor
+func (x *BulkUsersResponse) GetMissingIds() []int64 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
 
-const file_proto_user_user_proto_rawDesc = "" +
-	"\n" +
-	"\x15proto/user/user.proto\x12\x04user\"I\n" +
-	"\x11AreFriendsRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
-	"\tfriend_id\x18\x02 \x01(\x03R\bfriendId\"5\n" +
-	"\x12AreFriendsResponse\x12\x1f\n" +
-	"\vare_friends\x18\x01 \x01(\bR\n" +
-	"areFriends\")\n" +
-	"\x0eGetUserRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\\\n" +
-	"\x0fGetUserResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
-	"\busername\x18\x02 \x01(\tR\busername\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\tR\tcreatedAt\"$\n" +
-	"\x10BulkUsersRequest\x12\x10\n" +
-	"\x03ids\x18\x01 \x03(\x03R\x03ids\"@\n" +
-	"\x11BulkUsersResponse\x12+\n" +
-	"\x05users\x18\x01 \x03(\v2\x15.user.GetUserResponseR\x05users2\xc5\x01\n" +
+func (x *BulkUsersResponse) GetErrors() []*BulkUserError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type BulkUserError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// gRPC status code name returned by auth-service, e.g. "Unavailable".
+	Code          string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkUserError) Reset() {
+	*x = BulkUserError{}
+	mi := &file_proto_user_user_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkUserError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkUserError) ProtoMessage() {}
+
+func (x *BulkUserError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkUserError.ProtoReflect.Descriptor instead.
+func (*BulkUserError) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BulkUserError) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *BulkUserError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *BulkUserError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type WatchFriendshipsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Replay retained events after this sequence before streaming live ones.
+	// Zero streams only new events. Sequences restart with the service, so a
+	// cursor older than the retained history or newer than the latest event
+	// fails with OUT_OF_RANGE; reload state and watch from zero.
+	AfterSequence uint64 `protobuf:"varint,2,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchFriendshipsRequest) Reset() {
+	*x = WatchFriendshipsRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchFriendshipsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchFriendshipsRequest) ProtoMessage() {}
+
+func (x *WatchFriendshipsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchFriendshipsRequest.ProtoReflect.Descriptor instead.
+func (*WatchFriendshipsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchFriendshipsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *WatchFriendshipsRequest) GetAfterSequence() uint64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+type FriendshipEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Type          FriendshipEventType    `protobuf:"varint,2,opt,name=type,proto3,enum=user.FriendshipEventType" json:"type,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	OtherUserId   int64                  `protobuf:"varint,4,opt,name=other_user_id,json=otherUserId,proto3" json:"other_user_id,omitempty"`
+	RequestId     int64                  `protobuf:"varint,5,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	OccurredAt    string                 `protobuf:"bytes,6,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FriendshipEvent) Reset() {
+	*x = FriendshipEvent{}
+	mi := &file_proto_user_user_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FriendshipEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FriendshipEvent) ProtoMessage() {}
+
+func (x *FriendshipEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FriendshipEvent.ProtoReflect.Descriptor instead.
+func (*FriendshipEvent) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FriendshipEvent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *FriendshipEvent) GetType() FriendshipEventType {
+	if x != nil {
+		return x.Type
+	}
+	return FriendshipEventType_FRIENDSHIP_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *FriendshipEvent) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *FriendshipEvent) GetOtherUserId() int64 {
+	if x != nil {
+		return x.OtherUserId
+	}
+	return 0
+}
+
+func (x *FriendshipEvent) GetRequestId() int64 {
+	if x != nil {
+		return x.RequestId
+	}
+	return 0
+}
+
+func (x *FriendshipEvent) GetOccurredAt() string {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return ""
+}
+
+type ListFriendsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFriendsRequest) Reset() {
+	*x = ListFriendsRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFriendsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFriendsRequest) ProtoMessage() {}
+
+func (x *ListFriendsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFriendsRequest.ProtoReflect.Descriptor instead.
+func (*ListFriendsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListFriendsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListFriendsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FriendIds     []int64                `protobuf:"varint,1,rep,packed,name=friend_ids,json=friendIds,proto3" json:"friend_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFriendsResponse) Reset() {
+	*x = ListFriendsResponse{}
+	mi := &file_proto_user_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFriendsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFriendsResponse) ProtoMessage() {}
+
+func (x *ListFriendsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFriendsResponse.ProtoReflect.Descriptor instead.
+func (*ListFriendsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListFriendsResponse) GetFriendIds() []int64 {
+	if x != nil {
+		return x.FriendIds
+	}
+	return nil
+}
+
+type FriendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	FromUserId    int64                  `protobuf:"varint,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`
+	ToUserId      int64                  `protobuf:"varint,3,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FriendRequest) Reset() {
+	*x = FriendRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FriendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FriendRequest) ProtoMessage() {}
+
+func (x *FriendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FriendRequest.ProtoReflect.Descriptor instead.
+func (*FriendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FriendRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FriendRequest) GetFromUserId() int64 {
+	if x != nil {
+		return x.FromUserId
+	}
+	return 0
+}
+
+func (x *FriendRequest) GetToUserId() int64 {
+	if x != nil {
+		return x.ToUserId
+	}
+	return 0
+}
+
+func (x *FriendRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *FriendRequest) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListFriendRequestsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFriendRequestsRequest) Reset() {
+	*x = ListFriendRequestsRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFriendRequestsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFriendRequestsRequest) ProtoMessage() {}
+
+func (x *ListFriendRequestsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFriendRequestsRequest.ProtoReflect.Descriptor instead.
+func (*ListFriendRequestsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListFriendRequestsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListFriendRequestsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*FriendRequest       `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFriendRequestsResponse) Reset() {
+	*x = ListFriendRequestsResponse{}
+	mi := &file_proto_user_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFriendRequestsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFriendRequestsResponse) ProtoMessage() {}
+
+func (x *ListFriendRequestsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFriendRequestsResponse.ProtoReflect.Descriptor instead.
+func (*ListFriendRequestsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListFriendRequestsResponse) GetRequests() []*FriendRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type CountFriendsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountFriendsRequest) Reset() {
+	*x = CountFriendsRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountFriendsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountFriendsRequest) ProtoMessage() {}
+
+func (x *CountFriendsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountFriendsRequest.ProtoReflect.Descriptor instead.
+func (*CountFriendsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CountFriendsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type CountFriendsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountFriendsResponse) Reset() {
+	*x = CountFriendsResponse{}
+	mi := &file_proto_user_user_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountFriendsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountFriendsResponse) ProtoMessage() {}
+
+func (x *CountFriendsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountFriendsResponse.ProtoReflect.Descriptor instead.
+func (*CountFriendsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CountFriendsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type AreFriendsBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CandidateIds  []int64                `protobuf:"varint,2,rep,packed,name=candidate_ids,json=candidateIds,proto3" json:"candidate_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AreFriendsBatchRequest) Reset() {
+	*x = AreFriendsBatchRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AreFriendsBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AreFriendsBatchRequest) ProtoMessage() {}
+
+func (x *AreFriendsBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AreFriendsBatchRequest.ProtoReflect.Descriptor instead.
+func (*AreFriendsBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AreFriendsBatchRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AreFriendsBatchRequest) GetCandidateIds() []int64 {
+	if x != nil {
+		return x.CandidateIds
+	}
+	return nil
+}
+
+type AreFriendsBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Keyed by candidate id; every requested candidate is present.
+	AreFriends    map[int64]bool `protobuf:"bytes,1,rep,name=are_friends,json=areFriends,proto3" json:"are_friends,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AreFriendsBatchResponse) Reset() {
+	*x = AreFriendsBatchResponse{}
+	mi := &file_proto_user_user_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AreFriendsBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AreFriendsBatchResponse) ProtoMessage() {}
+
+func (x *AreFriendsBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AreFriendsBatchResponse.ProtoReflect.Descriptor instead.
+func (*AreFriendsBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AreFriendsBatchResponse) GetAreFriends() map[int64]bool {
+	if x != nil {
+		return x.AreFriends
+	}
+	return nil
+}
+
+// DeleteUserData removes a deleted account's friendships and friend
+// requests. Repeating the call is safe and removes nothing further.
+type DeleteUserDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserDataRequest) Reset() {
+	*x = DeleteUserDataRequest{}
+	mi := &file_proto_user_user_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserDataRequest) ProtoMessage() {}
+
+func (x *DeleteUserDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserDataRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteUserDataRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type DeleteUserDataResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RemovedFriendIds []int64                `protobuf:"varint,1,rep,packed,name=removed_friend_ids,json=removedFriendIds,proto3" json:"removed_friend_ids,omitempty"`
+	DeletedRequests  int64                  `protobuf:"varint,2,opt,name=deleted_requests,json=deletedRequests,proto3" json:"deleted_requests,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeleteUserDataResponse) Reset() {
+	*x = DeleteUserDataResponse{}
+	mi := &file_proto_user_user_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserDataResponse) ProtoMessage() {}
+
+func (x *DeleteUserDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_user_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserDataResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserDataResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_user_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteUserDataResponse) GetRemovedFriendIds() []int64 {
+	if x != nil {
+		return x.RemovedFriendIds
+	}
+	return nil
+}
+
+func (x *DeleteUserDataResponse) GetDeletedRequests() int64 {
+	if x != nil {
+		return x.DeletedRequests
+	}
+	return 0
+}
+
+var File_proto_user_user_proto protoreflect.FileDescriptor
+
+const file_proto_user_user_proto_rawDesc = "" +
+	"\n" +
+	"\x15proto/user/user.proto\x12\x04user\"I\n" +
+	"\x11AreFriendsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tfriend_id\x18\x02 \x01(\x03R\bfriendId\"5\n" +
+	"\x12AreFriendsResponse\x12\x1f\n" +
+	"\vare_friends\x18\x01 \x01(\bR\n" +
+	"areFriends\")\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\\\n" +
+	"\x0fGetUserResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\tR\tcreatedAt\"$\n" +
+	"\x10BulkUsersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"\x8e\x01\n" +
+	"\x11BulkUsersResponse\x12+\n" +
+	"\x05users\x18\x01 \x03(\v2\x15.user.GetUserResponseR\x05users\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x03R\n" +
+	"missingIds\x12+\n" +
+	"\x06errors\x18\x03 \x03(\v2\x13.user.BulkUserErrorR\x06errors\"M\n" +
+	"\rBulkUserError\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"Y\n" +
+	"\x17WatchFriendshipsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12%\n" +
+	"\x0eafter_sequence\x18\x02 \x01(\x04R\rafterSequence\"\xd9\x01\n" +
+	"\x0fFriendshipEvent\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12-\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x19.user.FriendshipEventTypeR\x04type\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\"\n" +
+	"\rother_user_id\x18\x04 \x01(\x03R\votherUserId\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x05 \x01(\x03R\trequestId\x12\x1f\n" +
+	"\voccurred_at\x18\x06 \x01(\tR\n" +
+	"occurredAt\"-\n" +
+	"\x12ListFriendsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"4\n" +
+	"\x13ListFriendsResponse\x12\x1d\n" +
+	"\n" +
+	"friend_ids\x18\x01 \x03(\x03R\tfriendIds\"\x96\x01\n" +
+	"\rFriendRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\x03R\n" +
+	"fromUserId\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\x03 \x01(\x03R\btoUserId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"4\n" +
+	"\x19ListFriendRequestsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"M\n" +
+	"\x1aListFriendRequestsResponse\x12/\n" +
+	"\brequests\x18\x01 \x03(\v2\x13.user.FriendRequestR\brequests\".\n" +
+	"\x13CountFriendsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\",\n" +
+	"\x14CountFriendsResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"V\n" +
+	"\x16AreFriendsBatchRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12#\n" +
+	"\rcandidate_ids\x18\x02 \x03(\x03R\fcandidateIds\"\xa8\x01\n" +
+	"\x17AreFriendsBatchResponse\x12N\n" +
+	"\vare_friends\x18\x01 \x03(\v2-.user.AreFriendsBatchResponse.AreFriendsEntryR\n" +
+	"areFriends\x1a=\n" +
+	"\x0fAreFriendsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x03R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"0\n" +
+	"\x15DeleteUserDataRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"q\n" +
+	"\x16DeleteUserDataResponse\x12,\n" +
+	"\x12removed_friend_ids\x18\x01 \x03(\x03R\x10removedFriendIds\x12)\n" +
+	"\x10deleted_requests\x18\x02 \x01(\x03R\x0fdeletedRequests*\x9b\x02\n" +
+	"\x13FriendshipEventType\x12%\n" +
+	"!FRIENDSHIP_EVENT_TYPE_UNSPECIFIED\x10\x00\x12)\n" +
+	"%FRIENDSHIP_EVENT_TYPE_REQUEST_CREATED\x10\x01\x12*\n" +
+	"&FRIENDSHIP_EVENT_TYPE_REQUEST_ACCEPTED\x10\x02\x12*\n" +
+	"&FRIENDSHIP_EVENT_TYPE_REQUEST_REJECTED\x10\x03\x12,\n" +
+	"(FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_CREATED\x10\x04\x12,\n" +
+	"(FRIENDSHIP_EVENT_TYPE_FRIENDSHIP_REMOVED\x10\x052\xef\x05\n" +
 	"\fUserInternal\x12?\n" +
 	"\n" +
 	"AreFriends\x12\x17.user.AreFriendsRequest\x1a\x18.user.AreFriendsResponse\x126\n" +
 	"\aGetUser\x12\x14.user.GetUserRequest\x1a\x15.user.GetUserResponse\x12<\n" +
-	"\tBulkUsers\x12\x16.user.BulkUsersRequest\x1a\x17.user.BulkUsersResponseB Z\x1euser-service/proto/user;userpbb\x06proto3"
+	"\tBulkUsers\x12\x16.user.BulkUsersRequest\x1a\x17.user.BulkUsersResponse\x12J\n" +
+	"\x10WatchFriendships\x12\x1d.user.WatchFriendshipsRequest\x1a\x15.user.FriendshipEvent0\x01\x12B\n" +
+	"\vListFriends\x12\x18.user.ListFriendsRequest\x1a\x19.user.ListFriendsResponse\x12Y\n" +
+	"\x14ListIncomingRequests\x12\x1f.user.ListFriendRequestsRequest\x1a .user.ListFriendRequestsResponse\x12Y\n" +
+	"\x14ListOutgoingRequests\x12\x1f.user.ListFriendRequestsRequest\x1a .user.ListFriendRequestsResponse\x12E\n" +
+	"\fCountFriends\x12\x19.user.CountFriendsRequest\x1a\x1a.user.CountFriendsResponse\x12N\n" +
+	"\x0fAreFriendsBatch\x12\x1c.user.AreFriendsBatchRequest\x1a\x1d.user.AreFriendsBatchResponse\x12K\n" +
+	"\x0eDeleteUserData\x12\x1b.user.DeleteUserDataRequest\x1a\x1c.user.DeleteUserDataResponseB Z\x1euser-service/proto/user;userpbb\x06proto3"
 
 var (
 	file_proto_user_user_proto_rawDescOnce sync.Once
@@ -349,28 +1231,63 @@ func file_proto_user_user_proto_rawDescGZIP() []byte {
 	return file_proto_user_user_proto_rawDescData
 }
 
-var file_proto_user_user_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_user_user_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_user_user_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_proto_user_user_proto_goTypes = []any{
-	(*AreFriendsRequest)(nil),  // 0: user.AreFriendsRequest
-	(*AreFriendsResponse)(nil), // 1: user.AreFriendsResponse
-	(*GetUserRequest)(nil),     // 2: user.GetUserRequest
-	(*GetUserResponse)(nil),    // 3: user.GetUserResponse
-	(*BulkUsersRequest)(nil),   // 4: user.BulkUsersRequest
-	(*BulkUsersResponse)(nil),  // 5: user.BulkUsersResponse
+	(FriendshipEventType)(0),           // 0: user.FriendshipEventType
+	(*AreFriendsRequest)(nil),          // 1: user.AreFriendsRequest
+	(*AreFriendsResponse)(nil),         // 2: user.AreFriendsResponse
+	(*GetUserRequest)(nil),             // 3: user.GetUserRequest
+	(*GetUserResponse)(nil),            // 4: user.GetUserResponse
+	(*BulkUsersRequest)(nil),           // 5: user.BulkUsersRequest
+	(*BulkUsersResponse)(nil),          // 6: user.BulkUsersResponse
+	(*BulkUserError)(nil),              // 7: user.BulkUserError
+	(*WatchFriendshipsRequest)(nil),    // 8: user.WatchFriendshipsRequest
+	(*FriendshipEvent)(nil),            // 9: user.FriendshipEvent
+	(*ListFriendsRequest)(nil),         // 10: user.ListFriendsRequest
+	(*ListFriendsResponse)(nil),        // 11: user.ListFriendsResponse
+	(*FriendRequest)(nil),              // 12: user.FriendRequest
+	(*ListFriendRequestsRequest)(nil),  // 13: user.ListFriendRequestsRequest
+	(*ListFriendRequestsResponse)(nil), // 14: user.ListFriendRequestsResponse
+	(*CountFriendsRequest)(nil),        // 15: user.CountFriendsRequest
+	(*CountFriendsResponse)(nil),       // 16: user.CountFriendsResponse
+	(*AreFriendsBatchRequest)(nil),     // 17: user.AreFriendsBatchRequest
+	(*AreFriendsBatchResponse)(nil),    // 18: user.AreFriendsBatchResponse
+	(*DeleteUserDataRequest)(nil),      // 19: user.DeleteUserDataRequest
+	(*DeleteUserDataResponse)(nil),     // 20: user.DeleteUserDataResponse
+	nil,                                // 21: user.AreFriendsBatchResponse.AreFriendsEntry
 }
 var file_proto_user_user_proto_depIdxs = []int32{
-	3, // 0: user.BulkUsersResponse.users:type_name -> user.GetUserResponse
-	0, // 1: user.UserInternal.AreFriends:input_type -> user.AreFriendsRequest
-	2, // 2: user.UserInternal.GetUser:input_type -> user.GetUserRequest
-	4, // 3: user.UserInternal.BulkUsers:input_type -> user.BulkUsersRequest
-	1, // 4: user.UserInternal.AreFriends:output_type -> user.AreFriendsResponse
-	3, // 5: user.UserInternal.GetUser:output_type -> user.GetUserResponse
-	5, // 6: user.UserInternal.BulkUsers:output_type -> user.BulkUsersResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4,  // 0: user.BulkUsersResponse.users:type_name -> user.GetUserResponse
+	7,  // 1: user.BulkUsersResponse.errors:type_name -> user.BulkUserError
+	0,  // 2: user.FriendshipEvent.type:type_name -> user.FriendshipEventType
+	12, // 3: user.ListFriendRequestsResponse.requests:type_name -> user.FriendRequest
+	21, // 4: user.AreFriendsBatchResponse.are_friends:type_name -> user.AreFriendsBatchResponse.AreFriendsEntry
+	1,  // 5: user.UserInternal.AreFriends:input_type -> user.AreFriendsRequest
+	3,  // 6: user.UserInternal.GetUser:input_type -> user.GetUserRequest
+	5,  // 7: user.UserInternal.BulkUsers:input_type -> user.BulkUsersRequest
+	8,  // 8: user.UserInternal.WatchFriendships:input_type -> user.WatchFriendshipsRequest
+	10, // 9: user.UserInternal.ListFriends:input_type -> user.ListFriendsRequest
+	13, // 10: user.UserInternal.ListIncomingRequests:input_type -> user.ListFriendRequestsRequest
+	13, // 11: user.UserInternal.ListOutgoingRequests:input_type -> user.ListFriendRequestsRequest
+	15, // 12: user.UserInternal.CountFriends:input_type -> user.CountFriendsRequest
+	17, // 13: user.UserInternal.AreFriendsBatch:input_type -> user.AreFriendsBatchRequest
+	19, // 14: user.UserInternal.DeleteUserData:input_type -> user.DeleteUserDataRequest
+	2,  // 15: user.UserInternal.AreFriends:output_type -> user.AreFriendsResponse
+	4,  // 16: user.UserInternal.GetUser:output_type -> user.GetUserResponse
+	6,  // 17: user.UserInternal.BulkUsers:output_type -> user.BulkUsersResponse
+	9,  // 18: user.UserInternal.WatchFriendships:output_type -> user.FriendshipEvent
+	11, // 19: user.UserInternal.ListFriends:output_type -> user.ListFriendsResponse
+	14, // 20: user.UserInternal.ListIncomingRequests:output_type -> user.ListFriendRequestsResponse
+	14, // 21: user.UserInternal.ListOutgoingRequests:output_type -> user.ListFriendRequestsResponse
+	16, // 22: user.UserInternal.CountFriends:output_type -> user.CountFriendsResponse
+	18, // 23: user.UserInternal.AreFriendsBatch:output_type -> user.AreFriendsBatchResponse
+	20, // 24: user.UserInternal.DeleteUserData:output_type -> user.DeleteUserDataResponse
+	15, // [15:25] is the sub-list for method output_type
+	5,  // [5:15] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_user_user_proto_init() }
@@ -383,13 +1300,14 @@ func file_proto_user_user_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_user_user_proto_rawDesc), len(file_proto_user_user_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_user_user_proto_goTypes,
 		DependencyIndexes: file_proto_user_user_proto_depIdxs,
+		EnumInfos:         file_proto_user_user_proto_enumTypes,
 		MessageInfos:      file_proto_user_user_proto_msgTypes,
 	}.Build()
 	File_proto_user_user_proto = out.File
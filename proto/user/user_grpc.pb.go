@@ -19,9 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserInternal_AreFriends_FullMethodName = "/user.UserInternal/AreFriends"
-	UserInternal_GetUser_FullMethodName    = "/user.UserInternal/GetUser"
-	UserInternal_BulkUsers_FullMethodName  = "/user.UserInternal/BulkUsers"
+	UserInternal_AreFriends_FullMethodName           = "/user.UserInternal/AreFriends"
+	UserInternal_GetUser_FullMethodName              = "/user.UserInternal/GetUser"
+	UserInternal_BulkUsers_FullMethodName            = "/user.UserInternal/BulkUsers"
+	UserInternal_WatchFriendships_FullMethodName     = "/user.UserInternal/WatchFriendships"
+	UserInternal_ListFriends_FullMethodName          = "/user.UserInternal/ListFriends"
+	UserInternal_ListIncomingRequests_FullMethodName = "/user.UserInternal/ListIncomingRequests"
+	UserInternal_ListOutgoingRequests_FullMethodName = "/user.UserInternal/ListOutgoingRequests"
+	UserInternal_CountFriends_FullMethodName         = "/user.UserInternal/CountFriends"
+	UserInternal_AreFriendsBatch_FullMethodName      = "/user.UserInternal/AreFriendsBatch"
+	UserInternal_DeleteUserData_FullMethodName       = "/user.UserInternal/DeleteUserData"
 )
 
 // UserInternalClient is the client API for UserInternal service.
@@ -31,6 +38,13 @@ type UserInternalClient interface {
 	AreFriends(ctx context.Context, in *AreFriendsRequest, opts ...grpc.CallOption) (*AreFriendsResponse, error)
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
 	BulkUsers(ctx context.Context, in *BulkUsersRequest, opts ...grpc.CallOption) (*BulkUsersResponse, error)
+	WatchFriendships(ctx context.Context, in *WatchFriendshipsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FriendshipEvent], error)
+	ListFriends(ctx context.Context, in *ListFriendsRequest, opts ...grpc.CallOption) (*ListFriendsResponse, error)
+	ListIncomingRequests(ctx context.Context, in *ListFriendRequestsRequest, opts ...grpc.CallOption) (*ListFriendRequestsResponse, error)
+	ListOutgoingRequests(ctx context.Context, in *ListFriendRequestsRequest, opts ...grpc.CallOption) (*ListFriendRequestsResponse, error)
+	CountFriends(ctx context.Context, in *CountFriendsRequest, opts ...grpc.CallOption) (*CountFriendsResponse, error)
+	AreFriendsBatch(ctx context.Context, in *AreFriendsBatchRequest, opts ...grpc.CallOption) (*AreFriendsBatchResponse, error)
+	DeleteUserData(ctx context.Context, in *DeleteUserDataRequest, opts ...grpc.CallOption) (*DeleteUserDataResponse, error)
 }
 
 type userInternalClient struct {
@@ -71,6 +85,85 @@ func (c *userInternalClient) BulkUsers(ctx context.Context, in *BulkUsersRequest
 	return out, nil
 }
 
+func (c *userInternalClient) WatchFriendships(ctx context.Context, in *WatchFriendshipsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FriendshipEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UserInternal_ServiceDesc.Streams[0], UserInternal_WatchFriendships_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchFriendshipsRequest, FriendshipEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UserInternal_WatchFriendshipsClient = grpc.ServerStreamingClient[FriendshipEvent]
+
+func (c *userInternalClient) ListFriends(ctx context.Context, in *ListFriendsRequest, opts ...grpc.CallOption) (*ListFriendsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFriendsResponse)
+	err := c.cc.Invoke(ctx, UserInternal_ListFriends_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userInternalClient) ListIncomingRequests(ctx context.Context, in *ListFriendRequestsRequest, opts ...grpc.CallOption) (*ListFriendRequestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFriendRequestsResponse)
+	err := c.cc.Invoke(ctx, UserInternal_ListIncomingRequests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userInternalClient) ListOutgoingRequests(ctx context.Context, in *ListFriendRequestsRequest, opts ...grpc.CallOption) (*ListFriendRequestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFriendRequestsResponse)
+	err := c.cc.Invoke(ctx, UserInternal_ListOutgoingRequests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userInternalClient) CountFriends(ctx context.Context, in *CountFriendsRequest, opts ...grpc.CallOption) (*CountFriendsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountFriendsResponse)
+	err := c.cc.Invoke(ctx, UserInternal_CountFriends_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userInternalClient) AreFriendsBatch(ctx context.Context, in *AreFriendsBatchRequest, opts ...grpc.CallOption) (*AreFriendsBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AreFriendsBatchResponse)
+	err := c.cc.Invoke(ctx, UserInternal_AreFriendsBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userInternalClient) DeleteUserData(ctx context.Context, in *DeleteUserDataRequest, opts ...grpc.CallOption) (*DeleteUserDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteUserDataResponse)
+	err := c.cc.Invoke(ctx, UserInternal_DeleteUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserInternalServer is the server API for UserInternal service.
 // All implementations must embed UnimplementedUserInternalServer
 // for forward compatibility.
@@ -78,6 +171,13 @@ type UserInternalServer interface {
 	AreFriends(context.Context, *AreFriendsRequest) (*AreFriendsResponse, error)
 	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
 	BulkUsers(context.Context, *BulkUsersRequest) (*BulkUsersResponse, error)
+	WatchFriendships(*WatchFriendshipsRequest, grpc.ServerStreamingServer[FriendshipEvent]) error
+	ListFriends(context.Context, *ListFriendsRequest) (*ListFriendsResponse, error)
+	ListIncomingRequests(context.Context, *ListFriendRequestsRequest) (*ListFriendRequestsResponse, error)
+	ListOutgoingRequests(context.Context, *ListFriendRequestsRequest) (*ListFriendRequestsResponse, error)
+	CountFriends(context.Context, *CountFriendsRequest) (*CountFriendsResponse, error)
+	AreFriendsBatch(context.Context, *AreFriendsBatchRequest) (*AreFriendsBatchResponse, error)
+	DeleteUserData(context.Context, *DeleteUserDataRequest) (*DeleteUserDataResponse, error)
 	mustEmbedUnimplementedUserInternalServer()
 }
 
@@ -97,6 +197,27 @@ func (UnimplementedUserInternalServer) GetUser(context.Context, *GetUserRequest)
 func (UnimplementedUserInternalServer) BulkUsers(context.Context, *BulkUsersRequest) (*BulkUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BulkUsers not implemented")
 }
+func (UnimplementedUserInternalServer) WatchFriendships(*WatchFriendshipsRequest, grpc.ServerStreamingServer[FriendshipEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchFriendships not implemented")
+}
+func (UnimplementedUserInternalServer) ListFriends(context.Context, *ListFriendsRequest) (*ListFriendsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFriends not implemented")
+}
+func (UnimplementedUserInternalServer) ListIncomingRequests(context.Context, *ListFriendRequestsRequest) (*ListFriendRequestsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIncomingRequests not implemented")
+}
+func (UnimplementedUserInternalServer) ListOutgoingRequests(context.Context, *ListFriendRequestsRequest) (*ListFriendRequestsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOutgoingRequests not implemented")
+}
+func (UnimplementedUserInternalServer) CountFriends(context.Context, *CountFriendsRequest) (*CountFriendsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountFriends not implemented")
+}
+func (UnimplementedUserInternalServer) AreFriendsBatch(context.Context, *AreFriendsBatchRequest) (*AreFriendsBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AreFriendsBatch not implemented")
+}
+func (UnimplementedUserInternalServer) DeleteUserData(context.Context, *DeleteUserDataRequest) (*DeleteUserDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserData not implemented")
+}
 func (UnimplementedUserInternalServer) mustEmbedUnimplementedUserInternalServer() {}
 func (UnimplementedUserInternalServer) testEmbeddedByValue()                      {}
 
@@ -172,6 +293,125 @@ func _UserInternal_BulkUsers_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserInternal_WatchFriendships_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchFriendshipsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserInternalServer).WatchFriendships(m, &grpc.GenericServerStream[WatchFriendshipsRequest, FriendshipEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UserInternal_WatchFriendshipsServer = grpc.ServerStreamingServer[FriendshipEvent]
+
+func _UserInternal_ListFriends_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFriendsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).ListFriends(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_ListFriends_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).ListFriends(ctx, req.(*ListFriendsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserInternal_ListIncomingRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFriendRequestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).ListIncomingRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_ListIncomingRequests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).ListIncomingRequests(ctx, req.(*ListFriendRequestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserInternal_ListOutgoingRequests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFriendRequestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).ListOutgoingRequests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_ListOutgoingRequests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).ListOutgoingRequests(ctx, req.(*ListFriendRequestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserInternal_CountFriends_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountFriendsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).CountFriends(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_CountFriends_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).CountFriends(ctx, req.(*CountFriendsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserInternal_AreFriendsBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AreFriendsBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).AreFriendsBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_AreFriendsBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).AreFriendsBatch(ctx, req.(*AreFriendsBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserInternal_DeleteUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserInternalServer).DeleteUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserInternal_DeleteUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserInternalServer).DeleteUserData(ctx, req.(*DeleteUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserInternal_ServiceDesc is the grpc.ServiceDesc for UserInternal service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,7 +431,37 @@ var UserInternal_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "BulkUsers",
 			Handler:    _UserInternal_BulkUsers_Handler,
 		},
+		{
+			MethodName: "ListFriends",
+			Handler:    _UserInternal_ListFriends_Handler,
+		},
+		{
+			MethodName: "ListIncomingRequests",
+			Handler:    _UserInternal_ListIncomingRequests_Handler,
+		},
+		{
+			MethodName: "ListOutgoingRequests",
+			Handler:    _UserInternal_ListOutgoingRequests_Handler,
+		},
+		{
+			MethodName: "CountFriends",
+			Handler:    _UserInternal_CountFriends_Handler,
+		},
+		{
+			MethodName: "AreFriendsBatch",
+			Handler:    _UserInternal_AreFriendsBatch_Handler,
+		},
+		{
+			MethodName: "DeleteUserData",
+			Handler:    _UserInternal_DeleteUserData_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchFriendships",
+			Handler:       _UserInternal_WatchFriendships_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/user/user.proto",
 }